@@ -14,34 +14,166 @@
 
 package deque
 
-import "github.com/igevin/algokit/collection/list"
+import (
+	"github.com/igevin/algokit/collection/list"
+	"github.com/igevin/algokit/internal/slice"
+)
 
+var _ Deque[any] = &ArrayDequeue[any]{}
+
+const arrayDequeMinCapacity = 8
+
+// ArrayDequeue 基于环形缓冲区的双端队列，两端的增删都是均摊 O(1)
+// 元素满了之后扩容到两倍；元素过少时按照 slice.CalCapacity 的策略缩容
+// 零值可以直接使用
+// 通过 NewFixedArrayDequeue 创建的双端队列容量固定，不会扩缩容
 type ArrayDequeue[T any] struct {
-	l list.ArrayList[T]
+	// data 的长度就是容量，有效元素是从 head 开始的 size 个，超过末尾之后回绕到开头
+	data []T
+	head int
+	size int
+	// fixed 为 true 时容量固定，满了之后放入元素返回 ErrFullDeque
+	fixed bool
+}
+
+// NewArrayDequeue 创建双端队列，capacity 是初始容量
+func NewArrayDequeue[T any](capacity int) *ArrayDequeue[T] {
+	if capacity < arrayDequeMinCapacity {
+		capacity = arrayDequeMinCapacity
+	}
+	return &ArrayDequeue[T]{
+		data: make([]T, capacity),
+	}
+}
+
+// NewFixedArrayDequeue 创建固定容量的双端队列，一次性分配 capacity 大小的缓冲区，之后不会扩缩容
+// capacity 最小为 1
+func NewFixedArrayDequeue[T any](capacity int) *ArrayDequeue[T] {
+	return &ArrayDequeue[T]{
+		data:  make([]T, max(capacity, 1)),
+		fixed: true,
+	}
 }
 
 func (q *ArrayDequeue[T]) AddFirst(t T) error {
-	return q.l.Add(0, t)
+	if err := q.growIfNecessary(); err != nil {
+		return err
+	}
+	q.head = q.index(len(q.data) - 1)
+	q.data[q.head] = t
+	q.size++
+	return nil
 }
 
 func (q *ArrayDequeue[T]) AddLast(t T) error {
-	return q.l.Append(t)
+	if err := q.growIfNecessary(); err != nil {
+		return err
+	}
+	q.data[q.index(q.size)] = t
+	q.size++
+	return nil
 }
 
 func (q *ArrayDequeue[T]) RemoveFirst() (T, error) {
-	return q.l.Delete(0)
+	var zero T
+	if q.size == 0 {
+		return zero, ErrEmptyDeque
+	}
+	t := q.data[q.head]
+	// 置为零值，避免内存泄露
+	q.data[q.head] = zero
+	q.head = q.index(1)
+	q.size--
+	q.shrinkIfNecessary()
+	return t, nil
 }
 
 func (q *ArrayDequeue[T]) RemoveLast() (T, error) {
-	i := q.l.Len() - 1
-	return q.l.Delete(i)
+	var zero T
+	if q.size == 0 {
+		return zero, ErrEmptyDeque
+	}
+	tail := q.index(q.size - 1)
+	t := q.data[tail]
+	q.data[tail] = zero
+	q.size--
+	q.shrinkIfNecessary()
+	return t, nil
 }
 
 func (q *ArrayDequeue[T]) PeekFirst() (T, error) {
-	return q.l.Get(0)
+	if q.size == 0 {
+		var zero T
+		return zero, ErrEmptyDeque
+	}
+	return q.data[q.head], nil
 }
 
 func (q *ArrayDequeue[T]) PeekLast() (T, error) {
-	i := q.l.Len() - 1
-	return q.l.Get(i)
+	if q.size == 0 {
+		var zero T
+		return zero, ErrEmptyDeque
+	}
+	return q.data[q.index(q.size-1)], nil
+}
+
+// Get 返回从队首开始的第 i 个元素，时间复杂度 O(1)
+func (q *ArrayDequeue[T]) Get(i int) (T, error) {
+	if i < 0 || i >= q.size {
+		var zero T
+		return zero, list.NewErrIndexOutOfRange(q.size, i)
+	}
+	return q.data[q.index(i)], nil
+}
+
+func (q *ArrayDequeue[T]) Len() int {
+	return q.size
+}
+
+func (q *ArrayDequeue[T]) Cap() int {
+	return len(q.data)
+}
+
+// index 把相对于队首的偏移量转换为 data 中的下标
+func (q *ArrayDequeue[T]) index(offset int) int {
+	i := q.head + offset
+	if i >= len(q.data) {
+		i -= len(q.data)
+	}
+	return i
+}
+
+func (q *ArrayDequeue[T]) growIfNecessary() error {
+	if q.size < len(q.data) {
+		return nil
+	}
+	if q.fixed {
+		return ErrFullDeque
+	}
+	c := len(q.data) * 2
+	if c < arrayDequeMinCapacity {
+		c = arrayDequeMinCapacity
+	}
+	q.resize(c)
+	return nil
+}
+
+func (q *ArrayDequeue[T]) shrinkIfNecessary() {
+	if q.fixed {
+		return
+	}
+	if c, ok := slice.CalCapacity(len(q.data), q.size); ok {
+		q.resize(c)
+	}
+}
+
+// resize 把元素按顺序搬到新的缓冲区，队首从 0 开始
+func (q *ArrayDequeue[T]) resize(capacity int) {
+	data := make([]T, capacity)
+	if q.size > 0 {
+		n := copy(data, q.data[q.head:min(q.head+q.size, len(q.data))])
+		copy(data[n:], q.data[:q.size-n])
+	}
+	q.data = data
+	q.head = 0
 }
@@ -0,0 +1,195 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deque
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/igevin/algokit/collection/list"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayDequeue_AddRemove(t *testing.T) {
+	testCases := []struct {
+		name string
+		q    func() *ArrayDequeue[int]
+	}{
+		{
+			name: "zero value",
+			q: func() *ArrayDequeue[int] {
+				return &ArrayDequeue[int]{}
+			},
+		},
+		{
+			name: "with capacity",
+			q: func() *ArrayDequeue[int] {
+				return NewArrayDequeue[int](3)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := tc.q()
+			// 两端交替增加，会在缓冲区末尾回绕，并且触发扩容
+			for i := 0; i < 20; i++ {
+				if i%2 == 0 {
+					require.NoError(t, q.AddFirst(i))
+				} else {
+					require.NoError(t, q.AddLast(i))
+				}
+			}
+			want := []int{18, 16, 14, 12, 10, 8, 6, 4, 2, 0, 1, 3, 5, 7, 9, 11, 13, 15, 17, 19}
+			assert.Equal(t, len(want), q.Len())
+			for i, w := range want {
+				v, err := q.Get(i)
+				require.NoError(t, err)
+				assert.Equal(t, w, v)
+			}
+			first, err := q.PeekFirst()
+			require.NoError(t, err)
+			assert.Equal(t, 18, first)
+			last, err := q.PeekLast()
+			require.NoError(t, err)
+			assert.Equal(t, 19, last)
+
+			for len(want) > 0 {
+				v, err := q.RemoveFirst()
+				require.NoError(t, err)
+				assert.Equal(t, want[0], v)
+				want = want[1:]
+				if len(want) == 0 {
+					break
+				}
+				v, err = q.RemoveLast()
+				require.NoError(t, err)
+				assert.Equal(t, want[len(want)-1], v)
+				want = want[:len(want)-1]
+			}
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
+
+func TestArrayDequeue_Empty(t *testing.T) {
+	q := NewArrayDequeue[int](0)
+	_, err := q.RemoveFirst()
+	assert.Equal(t, ErrEmptyDeque, err)
+	_, err = q.RemoveLast()
+	assert.Equal(t, ErrEmptyDeque, err)
+	_, err = q.PeekFirst()
+	assert.Equal(t, ErrEmptyDeque, err)
+	_, err = q.PeekLast()
+	assert.Equal(t, ErrEmptyDeque, err)
+	_, err = q.Get(0)
+	assert.ErrorIs(t, err, list.ErrIndexOutOfRange)
+	_, err = q.Get(-1)
+	assert.ErrorIs(t, err, list.ErrIndexOutOfRange)
+}
+
+func TestArrayDequeue_Resize(t *testing.T) {
+	q := NewArrayDequeue[int](arrayDequeMinCapacity)
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, q.AddLast(i))
+	}
+	assert.Equal(t, 1024, q.Cap())
+	for i := 0; i < 990; i++ {
+		v, err := q.RemoveFirst()
+		require.NoError(t, err)
+		require.Equal(t, i, v)
+	}
+	assert.LessOrEqual(t, q.Cap(), 64)
+	for i := 0; i < q.Len(); i++ {
+		v, err := q.Get(i)
+		require.NoError(t, err)
+		assert.Equal(t, 990+i, v)
+	}
+}
+
+func TestFixedArrayDequeue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		wantCap  int
+	}{
+		{
+			name:     "not positive",
+			capacity: 0,
+			wantCap:  1,
+		},
+		{
+			name:     "smaller than min capacity",
+			capacity: 3,
+			wantCap:  3,
+		},
+		{
+			name:     "large",
+			capacity: 3000,
+			wantCap:  3000,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewFixedArrayDequeue[int](tc.capacity)
+			// 反复填满再取空，容量始终不变
+			for round := 0; round < 2; round++ {
+				for i := 0; i < tc.wantCap; i++ {
+					add := q.AddLast
+					if i%2 == 0 {
+						add = q.AddFirst
+					}
+					require.NoError(t, add(i))
+				}
+				assert.Equal(t, ErrFullDeque, q.AddLast(-1))
+				assert.Equal(t, ErrFullDeque, q.AddFirst(-1))
+				assert.Equal(t, tc.wantCap, q.Cap())
+				for q.Len() > 0 {
+					_, err := q.RemoveFirst()
+					require.NoError(t, err)
+				}
+				assert.Equal(t, tc.wantCap, q.Cap())
+			}
+		})
+	}
+}
+
+// BenchmarkArrayDequeue_First 不同规模下，队首增删的耗时应该基本不变
+func BenchmarkArrayDequeue_First(b *testing.B) {
+	for _, n := range []int{1 << 10, 1 << 14, 1 << 18} {
+		b.Run(fmt.Sprintf("ring buffer %d", n), func(b *testing.B) {
+			q := NewArrayDequeue[int](n)
+			for i := 0; i < n; i++ {
+				_ = q.AddLast(i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = q.AddFirst(i)
+				_, _ = q.RemoveFirst()
+			}
+		})
+		b.Run(fmt.Sprintf("array list %d", n), func(b *testing.B) {
+			l := list.NewArrayListOf[int](make([]int, 0, n+1))
+			for i := 0; i < n; i++ {
+				_ = l.Append(i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = l.Add(0, i)
+				_, _ = l.Delete(0)
+			}
+		})
+	}
+}
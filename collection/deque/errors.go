@@ -14,31 +14,9 @@
 
 package deque
 
-import "github.com/igevin/algokit/collection/list"
+import "errors"
 
-type baseDeque[T any] struct {
-	l list.List[T]
-}
-
-func newBaseDeque[T any](l list.List[T]) baseDeque[T] {
-	return baseDeque[T]{
-		l: l,
-	}
-}
-
-func (q *baseDeque[T]) AddFirst(t T) error {
-	return q.l.Add(0, t)
-}
-
-func (q *baseDeque[T]) AddLast(t T) error {
-	return q.l.Append(t)
-}
-
-func (q *baseDeque[T]) RemoveFirst() (T, error) {
-	return q.l.Delete(0)
-}
-
-func (q *baseDeque[T]) RemoveLast() (T, error) {
-	i := q.l.Len() - 1
-	return q.l.Delete(i)
-}
+var (
+	ErrEmptyDeque = errors.New("algokit: 双端队列为空")
+	ErrFullDeque  = errors.New("algokit: 双端队列已满")
+)
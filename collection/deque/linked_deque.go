@@ -14,14 +14,134 @@
 
 package deque
 
-import "github.com/igevin/algokit/collection/list"
+import "iter"
 
+var _ Deque[any] = &LinkedDeque[any]{}
+
+// LinkedDeque 基于双向循环链表的双端队列，两端的增删查都是 O(1)
+// 使用一个哨兵节点，它的 next 是队首，prev 是队尾，这样不需要处理空指针
 type LinkedDeque[T any] struct {
-	baseDeque[T]
+	sentinel *linkedNode[T]
+	length   int
+}
+
+type linkedNode[T any] struct {
+	val        T
+	prev, next *linkedNode[T]
 }
 
 func NewLinkedDeque[T any]() *LinkedDeque[T] {
-	return &LinkedDeque[T]{
-		baseDeque: newBaseDeque[T](list.NewLinkedList[T]()),
+	q := &LinkedDeque[T]{}
+	q.init()
+	return q
+}
+
+func (q *LinkedDeque[T]) AddFirst(t T) error {
+	q.insertAfter(q.lazySentinel(), t)
+	return nil
+}
+
+func (q *LinkedDeque[T]) AddLast(t T) error {
+	q.insertAfter(q.lazySentinel().prev, t)
+	return nil
+}
+
+func (q *LinkedDeque[T]) RemoveFirst() (T, error) {
+	if q.length == 0 {
+		var zero T
+		return zero, ErrEmptyDeque
 	}
+	return q.remove(q.sentinel.next), nil
+}
+
+func (q *LinkedDeque[T]) RemoveLast() (T, error) {
+	if q.length == 0 {
+		var zero T
+		return zero, ErrEmptyDeque
+	}
+	return q.remove(q.sentinel.prev), nil
+}
+
+func (q *LinkedDeque[T]) PeekFirst() (T, error) {
+	if q.length == 0 {
+		var zero T
+		return zero, ErrEmptyDeque
+	}
+	return q.sentinel.next.val, nil
+}
+
+func (q *LinkedDeque[T]) PeekLast() (T, error) {
+	if q.length == 0 {
+		var zero T
+		return zero, ErrEmptyDeque
+	}
+	return q.sentinel.prev.val, nil
+}
+
+func (q *LinkedDeque[T]) Len() int {
+	return q.length
+}
+
+// Clear 清空队列
+func (q *LinkedDeque[T]) Clear() {
+	q.init()
+}
+
+// All 从队首到队尾遍历
+func (q *LinkedDeque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if q.length == 0 {
+			return
+		}
+		for n := q.sentinel.next; n != q.sentinel; n = n.next {
+			if !yield(n.val) {
+				return
+			}
+		}
+	}
+}
+
+// Backward 从队尾到队首遍历
+func (q *LinkedDeque[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if q.length == 0 {
+			return
+		}
+		for n := q.sentinel.prev; n != q.sentinel; n = n.prev {
+			if !yield(n.val) {
+				return
+			}
+		}
+	}
+}
+
+func (q *LinkedDeque[T]) init() {
+	s := &linkedNode[T]{}
+	s.prev, s.next = s, s
+	q.sentinel = s
+	q.length = 0
+}
+
+// lazySentinel 使得零值的 LinkedDeque 也可以直接使用
+func (q *LinkedDeque[T]) lazySentinel() *linkedNode[T] {
+	if q.sentinel == nil {
+		q.init()
+	}
+	return q.sentinel
+}
+
+func (q *LinkedDeque[T]) insertAfter(at *linkedNode[T], t T) {
+	n := &linkedNode[T]{val: t, prev: at, next: at.next}
+	at.next.prev = n
+	at.next = n
+	q.length++
+}
+
+func (q *LinkedDeque[T]) remove(n *linkedNode[T]) T {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	// 断开引用，便于 GC
+	n.prev, n.next = nil, nil
+	q.length--
+	return n.val
 }
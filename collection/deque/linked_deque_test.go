@@ -0,0 +1,125 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deque
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkedDeque_AddRemove(t *testing.T) {
+	testCases := []struct {
+		name string
+		q    func() *LinkedDeque[int]
+	}{
+		{
+			name: "zero value",
+			q: func() *LinkedDeque[int] {
+				return &LinkedDeque[int]{}
+			},
+		},
+		{
+			name: "new",
+			q:    NewLinkedDeque[int],
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := tc.q()
+			require.NoError(t, q.AddLast(2))
+			require.NoError(t, q.AddFirst(1))
+			require.NoError(t, q.AddLast(3))
+			require.NoError(t, q.AddFirst(0))
+			assert.Equal(t, 4, q.Len())
+			assert.Equal(t, []int{0, 1, 2, 3}, slices.Collect(q.All()))
+			assert.Equal(t, []int{3, 2, 1, 0}, slices.Collect(q.Backward()))
+
+			v, err := q.PeekFirst()
+			require.NoError(t, err)
+			assert.Equal(t, 0, v)
+			v, err = q.PeekLast()
+			require.NoError(t, err)
+			assert.Equal(t, 3, v)
+
+			v, err = q.RemoveLast()
+			require.NoError(t, err)
+			assert.Equal(t, 3, v)
+			v, err = q.RemoveFirst()
+			require.NoError(t, err)
+			assert.Equal(t, 0, v)
+			v, err = q.RemoveLast()
+			require.NoError(t, err)
+			assert.Equal(t, 2, v)
+			v, err = q.RemoveLast()
+			require.NoError(t, err)
+			assert.Equal(t, 1, v)
+			assert.Equal(t, 0, q.Len())
+			assert.Empty(t, slices.Collect(q.All()))
+		})
+	}
+}
+
+func TestLinkedDeque_Empty(t *testing.T) {
+	testCases := []struct {
+		name string
+		q    *LinkedDeque[int]
+	}{
+		{name: "zero value", q: &LinkedDeque[int]{}},
+		{name: "new", q: NewLinkedDeque[int]()},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.q.RemoveFirst()
+			assert.Equal(t, ErrEmptyDeque, err)
+			_, err = tc.q.RemoveLast()
+			assert.Equal(t, ErrEmptyDeque, err)
+			_, err = tc.q.PeekFirst()
+			assert.Equal(t, ErrEmptyDeque, err)
+			_, err = tc.q.PeekLast()
+			assert.Equal(t, ErrEmptyDeque, err)
+			assert.Empty(t, slices.Collect(tc.q.Backward()))
+		})
+	}
+}
+
+func TestLinkedDeque_Clear(t *testing.T) {
+	q := NewLinkedDeque[int]()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, q.AddLast(i))
+	}
+	q.Clear()
+	assert.Equal(t, 0, q.Len())
+	assert.Empty(t, slices.Collect(q.All()))
+	require.NoError(t, q.AddFirst(10))
+	assert.Equal(t, []int{10}, slices.Collect(q.All()))
+}
+
+func TestLinkedDeque_IterateBreak(t *testing.T) {
+	q := NewLinkedDeque[int]()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, q.AddLast(i))
+	}
+	res := make([]int, 0, 2)
+	for v := range q.Backward() {
+		res = append(res, v)
+		if len(res) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int{4, 3}, res)
+}
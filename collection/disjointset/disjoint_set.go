@@ -0,0 +1,141 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disjointset
+
+// DisjointSet 并查集，采用按大小合并和路径压缩，单次操作的均摊时间复杂度接近 O(1)
+// 元素在内部被映射为连续的下标，parent 和 size 都用切片保存
+type DisjointSet[T comparable] struct {
+	index  map[T]int
+	elems  []T
+	parent []int
+	// size 只有根节点上的值有意义，表示整个集合的大小
+	size  []int
+	count int
+}
+
+func NewDisjointSet[T comparable]() *DisjointSet[T] {
+	return &DisjointSet[T]{
+		index: make(map[T]int),
+	}
+}
+
+// MakeSet 创建只包含 t 的集合，如果 t 已经存在，返回 false
+func (d *DisjointSet[T]) MakeSet(t T) bool {
+	if _, ok := d.index[t]; ok {
+		return false
+	}
+	d.index[t] = len(d.elems)
+	d.parent = append(d.parent, len(d.elems))
+	d.size = append(d.size, 1)
+	d.elems = append(d.elems, t)
+	d.count++
+	return true
+}
+
+// Find 返回 t 所在集合的代表元素
+func (d *DisjointSet[T]) Find(t T) (T, error) {
+	i, ok := d.index[t]
+	if !ok {
+		var res T
+		return res, ErrElementNotFound
+	}
+	return d.elems[d.find(i)], nil
+}
+
+// Union 合并 x 和 y 所在的集合，如果两者原本就在同一个集合，返回 false
+func (d *DisjointSet[T]) Union(x, y T) (bool, error) {
+	rx, ry, err := d.roots(x, y)
+	if err != nil || rx == ry {
+		return false, err
+	}
+	// 小集合挂到大集合下面，保证树高是 O(log n)
+	if d.size[rx] < d.size[ry] {
+		rx, ry = ry, rx
+	}
+	d.parent[ry] = rx
+	d.size[rx] += d.size[ry]
+	d.count--
+	return true, nil
+}
+
+// Connected 判断 x 和 y 是否在同一个集合中
+func (d *DisjointSet[T]) Connected(x, y T) (bool, error) {
+	rx, ry, err := d.roots(x, y)
+	if err != nil {
+		return false, err
+	}
+	return rx == ry, nil
+}
+
+// SetSize 返回 t 所在集合的大小
+func (d *DisjointSet[T]) SetSize(t T) (int, error) {
+	i, ok := d.index[t]
+	if !ok {
+		return 0, ErrElementNotFound
+	}
+	return d.size[d.find(i)], nil
+}
+
+// Count 返回集合的数量
+func (d *DisjointSet[T]) Count() int {
+	return d.count
+}
+
+// Len 返回元素的数量
+func (d *DisjointSet[T]) Len() int {
+	return len(d.elems)
+}
+
+// Groups 返回所有的集合，集合之间以及集合内元素的顺序都按照 MakeSet 的先后
+func (d *DisjointSet[T]) Groups() [][]T {
+	res := make([][]T, 0, d.count)
+	// group 记录根节点对应 res 中的下标
+	group := make(map[int]int, d.count)
+	for i, t := range d.elems {
+		r := d.find(i)
+		g, ok := group[r]
+		if !ok {
+			g = len(res)
+			group[r] = g
+			res = append(res, make([]T, 0, d.size[r]))
+		}
+		res[g] = append(res[g], t)
+	}
+	return res
+}
+
+func (d *DisjointSet[T]) roots(x, y T) (int, int, error) {
+	i, ok := d.index[x]
+	if !ok {
+		return 0, 0, ErrElementNotFound
+	}
+	j, ok := d.index[y]
+	if !ok {
+		return 0, 0, ErrElementNotFound
+	}
+	return d.find(i), d.find(j), nil
+}
+
+// find 返回下标 i 的根，并且把路径上的节点都直接挂到根上
+func (d *DisjointSet[T]) find(i int) int {
+	root := i
+	for d.parent[root] != root {
+		root = d.parent[root]
+	}
+	for d.parent[i] != root {
+		d.parent[i], i = root, d.parent[i]
+	}
+	return root
+}
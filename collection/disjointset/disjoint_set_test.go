@@ -0,0 +1,155 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disjointset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisjointSet_MakeSet(t *testing.T) {
+	d := NewDisjointSet[string]()
+	assert.True(t, d.MakeSet("a"))
+	assert.True(t, d.MakeSet("b"))
+	assert.False(t, d.MakeSet("a"))
+	assert.Equal(t, 2, d.Count())
+	assert.Equal(t, 2, d.Len())
+	root, err := d.Find("a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", root)
+	_, err = d.Find("c")
+	assert.Equal(t, ErrElementNotFound, err)
+}
+
+func TestDisjointSet_Union(t *testing.T) {
+	testCases := []struct {
+		name      string
+		unions    [][2]int
+		x, y      int
+		wantRes   bool
+		wantErr   error
+		wantCount int
+		wantSize  int
+	}{
+		{
+			name:      "separate",
+			x:         0,
+			y:         1,
+			wantRes:   true,
+			wantCount: 4,
+			wantSize:  2,
+		},
+		{
+			name:      "already connected",
+			unions:    [][2]int{{0, 1}, {1, 2}},
+			x:         2,
+			y:         0,
+			wantCount: 3,
+			wantSize:  3,
+		},
+		{
+			name:      "merge two groups",
+			unions:    [][2]int{{0, 1}, {2, 3}},
+			x:         1,
+			y:         3,
+			wantRes:   true,
+			wantCount: 2,
+			wantSize:  4,
+		},
+		{
+			name:      "not found",
+			x:         0,
+			y:         10,
+			wantErr:   ErrElementNotFound,
+			wantCount: 5,
+			wantSize:  1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newDisjointSetOf(5, tc.unions)
+			res, err := d.Union(tc.x, tc.y)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantCount, d.Count())
+			size, err := d.SetSize(tc.x)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantSize, size)
+		})
+	}
+}
+
+func TestDisjointSet_Connected(t *testing.T) {
+	d := newDisjointSetOf(6, [][2]int{{0, 1}, {2, 3}, {3, 4}})
+	testCases := []struct {
+		name    string
+		x, y    int
+		want    bool
+		wantErr error
+	}{
+		{name: "direct", x: 0, y: 1, want: true},
+		{name: "transitive", x: 2, y: 4, want: true},
+		{name: "self", x: 5, y: 5, want: true},
+		{name: "different", x: 1, y: 2},
+		{name: "not found", x: 1, y: 7, wantErr: ErrElementNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := d.Connected(tc.x, tc.y)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+	_, err := d.SetSize(7)
+	assert.Equal(t, ErrElementNotFound, err)
+}
+
+func TestDisjointSet_Groups(t *testing.T) {
+	d := newDisjointSetOf(7, [][2]int{{0, 3}, {5, 3}, {1, 6}})
+	assert.Equal(t, [][]int{{0, 3, 5}, {1, 6}, {2}, {4}}, d.Groups())
+	assert.Equal(t, [][]int{}, NewDisjointSet[int]().Groups())
+}
+
+func TestDisjointSet_PathCompression(t *testing.T) {
+	const n = 1000
+	d := newDisjointSetOf(n, nil)
+	for i := 1; i < n; i++ {
+		_, err := d.Union(i-1, i)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, d.Count())
+	root, err := d.Find(n - 1)
+	require.NoError(t, err)
+	r := d.index[root]
+	// 一次 Find 之后，路径上的节点都直接挂在根上
+	for i := 0; i < n; i++ {
+		_, err = d.Find(i)
+		require.NoError(t, err)
+		assert.Equal(t, r, d.parent[d.index[i]])
+	}
+}
+
+func newDisjointSetOf(n int, unions [][2]int) *DisjointSet[int] {
+	d := NewDisjointSet[int]()
+	for i := 0; i < n; i++ {
+		d.MakeSet(i)
+	}
+	for _, u := range unions {
+		_, _ = d.Union(u[0], u[1])
+	}
+	return d
+}
@@ -0,0 +1,188 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disjointset
+
+// RollbackDisjointSet 可回滚的并查集，适用于离线动态连通性、回溯搜索等场景
+// 为了能够撤销，不做路径压缩，只按大小合并，所以 Find 的时间复杂度是 O(log n)
+// 每一次 MakeSet 和成功的 Union 都会记录在历史栈中，回滚时按照相反的顺序逐个撤销，
+// 撤销单个操作是 O(1) 的
+type RollbackDisjointSet[T comparable] struct {
+	index   map[T]int
+	elems   []T
+	parent  []int
+	size    []int
+	count   int
+	history []rollbackRecord
+	// generation 每次 Rollback 真正撤销了记录之后加一，用于识别失效的快照
+	generation int
+}
+
+// rollbackRecord 记录一次操作，child 为 -1 时表示 MakeSet，否则表示把 child 挂到了 root 下
+type rollbackRecord struct {
+	child int
+	root  int
+	// generation 记录产生时并查集的 generation
+	generation int
+}
+
+// Snapshot 是 RollbackDisjointSet 在某一时刻的状态，可以通过 Rollback 回到这个状态
+type Snapshot struct {
+	version    int
+	generation int
+}
+
+func NewRollbackDisjointSet[T comparable]() *RollbackDisjointSet[T] {
+	return &RollbackDisjointSet[T]{
+		index: make(map[T]int),
+	}
+}
+
+// MakeSet 创建只包含 t 的集合，如果 t 已经存在，返回 false
+func (d *RollbackDisjointSet[T]) MakeSet(t T) bool {
+	if _, ok := d.index[t]; ok {
+		return false
+	}
+	d.index[t] = len(d.elems)
+	d.parent = append(d.parent, len(d.elems))
+	d.size = append(d.size, 1)
+	d.elems = append(d.elems, t)
+	d.count++
+	d.history = append(d.history, rollbackRecord{child: -1, generation: d.generation})
+	return true
+}
+
+// Find 返回 t 所在集合的代表元素
+func (d *RollbackDisjointSet[T]) Find(t T) (T, error) {
+	i, ok := d.index[t]
+	if !ok {
+		var res T
+		return res, ErrElementNotFound
+	}
+	return d.elems[d.find(i)], nil
+}
+
+// Union 合并 x 和 y 所在的集合，如果两者原本就在同一个集合，返回 false，并且不会记录历史
+func (d *RollbackDisjointSet[T]) Union(x, y T) (bool, error) {
+	rx, ry, err := d.roots(x, y)
+	if err != nil || rx == ry {
+		return false, err
+	}
+	if d.size[rx] < d.size[ry] {
+		rx, ry = ry, rx
+	}
+	d.parent[ry] = rx
+	d.size[rx] += d.size[ry]
+	d.count--
+	d.history = append(d.history, rollbackRecord{child: ry, root: rx, generation: d.generation})
+	return true, nil
+}
+
+// Connected 判断 x 和 y 是否在同一个集合中
+func (d *RollbackDisjointSet[T]) Connected(x, y T) (bool, error) {
+	rx, ry, err := d.roots(x, y)
+	if err != nil {
+		return false, err
+	}
+	return rx == ry, nil
+}
+
+// SetSize 返回 t 所在集合的大小
+func (d *RollbackDisjointSet[T]) SetSize(t T) (int, error) {
+	i, ok := d.index[t]
+	if !ok {
+		return 0, ErrElementNotFound
+	}
+	return d.size[d.find(i)], nil
+}
+
+// Count 返回集合的数量
+func (d *RollbackDisjointSet[T]) Count() int {
+	return d.count
+}
+
+// Len 返回元素的数量
+func (d *RollbackDisjointSet[T]) Len() int {
+	return len(d.elems)
+}
+
+// Snapshot 返回当前状态的快照
+func (d *RollbackDisjointSet[T]) Snapshot() Snapshot {
+	return Snapshot{version: len(d.history), generation: d.generation}
+}
+
+// Rollback 撤销 snapshot 之后的所有 MakeSet 和 Union
+// 回滚到更早的快照之后，比它晚的快照都会失效，再使用会返回 ErrInvalidSnapshot
+func (d *RollbackDisjointSet[T]) Rollback(snapshot Snapshot) error {
+	if !d.valid(snapshot) {
+		return ErrInvalidSnapshot
+	}
+	if len(d.history) > snapshot.version {
+		d.generation++
+	}
+	for len(d.history) > snapshot.version {
+		r := d.history[len(d.history)-1]
+		d.history = d.history[:len(d.history)-1]
+		if r.child < 0 {
+			d.undoMakeSet()
+			continue
+		}
+		d.parent[r.child] = r.child
+		d.size[r.root] -= d.size[r.child]
+		d.count++
+	}
+	return nil
+}
+
+// valid 判断快照是否还有效
+// 快照之前的最后一条记录如果产生于快照之后的 generation，说明它曾经被撤销、
+// 这个位置上现在是新的记录，快照对应的状态已经不存在了
+func (d *RollbackDisjointSet[T]) valid(snapshot Snapshot) bool {
+	if snapshot.version < 0 || snapshot.version > len(d.history) {
+		return false
+	}
+	return snapshot.version == 0 || d.history[snapshot.version-1].generation <= snapshot.generation
+}
+
+// undoMakeSet MakeSet 的撤销顺序和创建顺序相反，所以被撤销的一定是最后一个元素
+func (d *RollbackDisjointSet[T]) undoMakeSet() {
+	last := len(d.elems) - 1
+	delete(d.index, d.elems[last])
+	var zero T
+	d.elems[last] = zero
+	d.elems = d.elems[:last]
+	d.parent = d.parent[:last]
+	d.size = d.size[:last]
+	d.count--
+}
+
+func (d *RollbackDisjointSet[T]) roots(x, y T) (int, int, error) {
+	i, ok := d.index[x]
+	if !ok {
+		return 0, 0, ErrElementNotFound
+	}
+	j, ok := d.index[y]
+	if !ok {
+		return 0, 0, ErrElementNotFound
+	}
+	return d.find(i), d.find(j), nil
+}
+
+// find 不做路径压缩，否则撤销合并时无法恢复被修改的父节点
+func (d *RollbackDisjointSet[T]) find(i int) int {
+	for d.parent[i] != i {
+		i = d.parent[i]
+	}
+	return i
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disjointset
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackDisjointSet_Union(t *testing.T) {
+	d := NewRollbackDisjointSet[int]()
+	for i := 0; i < 4; i++ {
+		assert.True(t, d.MakeSet(i))
+	}
+	assert.False(t, d.MakeSet(0))
+
+	ok, err := d.Union(0, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = d.Union(1, 0)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, err = d.Union(0, 9)
+	assert.Equal(t, ErrElementNotFound, err)
+
+	connected, err := d.Connected(0, 1)
+	require.NoError(t, err)
+	assert.True(t, connected)
+	size, err := d.SetSize(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, size)
+	root0, err := d.Find(0)
+	require.NoError(t, err)
+	root1, err := d.Find(1)
+	require.NoError(t, err)
+	assert.Equal(t, root0, root1)
+	assert.Equal(t, 3, d.Count())
+	assert.Equal(t, 4, d.Len())
+}
+
+func TestRollbackDisjointSet_Rollback(t *testing.T) {
+	d := NewRollbackDisjointSet[string]()
+	d.MakeSet("a")
+	d.MakeSet("b")
+	d.MakeSet("c")
+	s1 := d.Snapshot()
+
+	_, _ = d.Union("a", "b")
+	s2 := d.Snapshot()
+
+	d.MakeSet("d")
+	_, _ = d.Union("c", "d")
+	_, _ = d.Union("a", "d")
+	assert.Equal(t, 1, d.Count())
+
+	require.NoError(t, d.Rollback(s2))
+	assert.Equal(t, 2, d.Count())
+	assert.Equal(t, 3, d.Len())
+	_, err := d.Find("d")
+	assert.Equal(t, ErrElementNotFound, err)
+	connected, err := d.Connected("a", "b")
+	require.NoError(t, err)
+	assert.True(t, connected)
+	connected, err = d.Connected("a", "c")
+	require.NoError(t, err)
+	assert.False(t, connected)
+
+	// 回滚之后可以继续操作，并且可以重新创建被撤销的元素
+	assert.True(t, d.MakeSet("d"))
+	require.NoError(t, d.Rollback(s1))
+	assert.Equal(t, 3, d.Count())
+	size, err := d.SetSize("a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, size)
+
+	// 比当前状态更晚的快照已经失效
+	assert.Equal(t, ErrInvalidSnapshot, d.Rollback(s2))
+	require.NoError(t, d.Rollback(Snapshot{}))
+	assert.Equal(t, 0, d.Len())
+	assert.Equal(t, 0, d.Count())
+}
+
+// TestRollbackDisjointSet_StaleSnapshot 回滚到更早的快照之后，历史重新增长到原来的长度，
+// 比它晚的快照依旧是失效的
+func TestRollbackDisjointSet_StaleSnapshot(t *testing.T) {
+	d := NewRollbackDisjointSet[int]()
+	for i := 0; i < 4; i++ {
+		d.MakeSet(i)
+	}
+	s0 := d.Snapshot()
+	_, _ = d.Union(0, 1)
+	s1 := d.Snapshot()
+	_, _ = d.Union(2, 3)
+	require.NoError(t, d.Rollback(s0))
+	_, _ = d.Union(0, 2)
+	_, _ = d.Union(1, 3)
+
+	assert.Equal(t, ErrInvalidSnapshot, d.Rollback(s1))
+	// 失效的快照不会改变状态
+	assert.Equal(t, 2, d.Count())
+	connected, err := d.Connected(0, 2)
+	require.NoError(t, err)
+	assert.True(t, connected)
+
+	// 更早的快照依旧有效，并且可以反复回滚
+	s2 := d.Snapshot()
+	require.NoError(t, d.Rollback(s0))
+	require.NoError(t, d.Rollback(s0))
+	assert.Equal(t, 4, d.Count())
+	assert.Equal(t, ErrInvalidSnapshot, d.Rollback(s2))
+}
+
+// TestRollbackDisjointSet_Random 随机合并和回滚，和重放同样操作的 DisjointSet 交叉验证
+func TestRollbackDisjointSet_Random(t *testing.T) {
+	const n = 50
+	r := rand.New(rand.NewSource(3))
+	d := NewRollbackDisjointSet[int]()
+	for i := 0; i < n; i++ {
+		d.MakeSet(i)
+	}
+	// unions 是当前生效的合并操作，snapshots 和 marks 一一对应
+	var unions [][2]int
+	var snapshots []Snapshot
+	var marks []int
+	for step := 0; step < 500; step++ {
+		switch op := r.Intn(10); {
+		case op < 6:
+			x, y := r.Intn(n), r.Intn(n)
+			_, err := d.Union(x, y)
+			require.NoError(t, err)
+			unions = append(unions, [2]int{x, y})
+		case op < 8:
+			snapshots = append(snapshots, d.Snapshot())
+			marks = append(marks, len(unions))
+		case len(snapshots) > 0:
+			i := r.Intn(len(snapshots))
+			require.NoError(t, d.Rollback(snapshots[i]))
+			unions = unions[:marks[i]]
+			snapshots, marks = snapshots[:i+1], marks[:i+1]
+		}
+
+		want := newDisjointSetOf(n, unions)
+		require.Equal(t, want.Count(), d.Count())
+		for i := 0; i < 10; i++ {
+			x, y := r.Intn(n), r.Intn(n)
+			wantConnected, err := want.Connected(x, y)
+			require.NoError(t, err)
+			connected, err := d.Connected(x, y)
+			require.NoError(t, err)
+			require.Equal(t, wantConnected, connected)
+			wantSize, err := want.SetSize(x)
+			require.NoError(t, err)
+			size, err := d.SetSize(x)
+			require.NoError(t, err)
+			require.Equal(t, wantSize, size)
+		}
+	}
+}
@@ -14,6 +14,17 @@
 
 package mapx
 
+import "reflect"
+
+const (
+	// hashMapMinCapacity 桶数组的最小长度，缩容不会低于这个值
+	hashMapMinCapacity = 8
+	// hashMapRehashStep 每次操作最多迁移的桶数
+	hashMapRehashStep = 4
+	// hashMapMaxEmptyVisits 每次操作最多跳过的空桶数，避免在稀疏的表上一次迁移扫描太多桶
+	hashMapMaxEmptyVisits = hashMapRehashStep * 10
+)
+
 type Hashable interface {
 	// Code 返回该元素的哈希值
 	// 注意：哈希值应该尽可能的均匀以避免冲突
@@ -22,56 +33,257 @@ type Hashable interface {
 	Equals(key any) bool
 }
 
+var _ Map[Hashable, any] = &HashMap[Hashable, any]{}
+
 // HashMap 是一个哈希表，采用渐进式扩容
+// 扩容或者缩容的时候，会同时持有新旧两个桶数组，
+// 之后每一次 Get、Put、Delete 都只迁移少量的桶，
+// 从而把迁移的开销分摊到各次操作上，避免单次操作耗时突增
+// 零值可以直接使用，第一次放入元素的时候才会分配桶数组
 type HashMap[K Hashable, V any] struct {
+	// tables[0] 是当前使用的桶数组，tables[1] 只在迁移期间非 nil
+	tables [2][]*hashNode[K, V]
+	// rehashIdx 是 tables[0] 中下一个待迁移的桶，只在迁移期间有意义
+	rehashIdx int
+	size      int
+}
+
+type hashNode[K Hashable, V any] struct {
+	key   K
+	value V
+	next  *hashNode[K, V]
+}
+
+// NewHashMap 创建 HashMap，size 是预估的元素数量
+func NewHashMap[K Hashable, V any](size int) *HashMap[K, V] {
+	return &HashMap[K, V]{
+		tables: [2][]*hashNode[K, V]{make([]*hashNode[K, V], hashCapacity(size))},
+	}
+}
+
+// hashCapacity 返回不小于 size 的 2 的幂，且不小于 hashMapMinCapacity
+func hashCapacity(size int) int {
+	c := hashMapMinCapacity
+	for c < size {
+		c <<= 1
+	}
+	return c
+}
+
+func hashIndex(code uint64, capacity int) int {
+	return int(code & uint64(capacity-1))
 }
 
 func (h *HashMap[K, V]) Keys() []K {
-	//TODO implement me
-	panic("implement me")
+	res := make([]K, 0, h.size)
+	h.each(func(n *hashNode[K, V]) {
+		res = append(res, n.key)
+	})
+	return res
 }
 
 func (h *HashMap[K, V]) Values() []V {
-	//TODO implement me
-	panic("implement me")
+	res := make([]V, 0, h.size)
+	h.each(func(n *hashNode[K, V]) {
+		res = append(res, n.value)
+	})
+	return res
 }
 
-func (h *HashMap[K, V]) KeysValues() []V {
-	//TODO implement me
-	panic("implement me")
+func (h *HashMap[K, V]) KeysValues() ([]K, []V) {
+	keys := make([]K, 0, h.size)
+	values := make([]V, 0, h.size)
+	h.each(func(n *hashNode[K, V]) {
+		keys = append(keys, n.key)
+		values = append(values, n.value)
+	})
+	return keys, values
 }
 
 func (h *HashMap[K, V]) Get(key K) (V, bool) {
-	//TODO implement me
-	panic("implement me")
+	h.rehashStep()
+	if n := h.findNode(key); n != nil {
+		return n.value, true
+	}
+	var v V
+	return v, false
 }
 
 func (h *HashMap[K, V]) GetOrDefault(key K, value V) V {
-	//TODO implement me
-	panic("implement me")
+	if v, ok := h.Get(key); ok {
+		return v
+	}
+	return value
 }
 
 func (h *HashMap[K, V]) Put(key K, value V) (V, error) {
-	//TODO implement me
-	panic("implement me")
+	h.rehashStep()
+	if n := h.findNode(key); n != nil {
+		old := n.value
+		n.value = value
+		return old, nil
+	}
+	h.insert(key, value)
+	var v V
+	return v, nil
 }
 
 func (h *HashMap[K, V]) PutIfAbsent(key K, value V) (V, error) {
-	//TODO implement me
-	panic("implement me")
+	h.rehashStep()
+	if n := h.findNode(key); n != nil {
+		return n.value, nil
+	}
+	h.insert(key, value)
+	var v V
+	return v, nil
 }
 
 func (h *HashMap[K, V]) Delete(key K) (V, error) {
-	//TODO implement me
-	panic("implement me")
+	h.rehashStep()
+	n := h.removeNode(key, func(V) bool { return true })
+	if n == nil {
+		var v V
+		return v, ErrKeyNotFound
+	}
+	return n.value, nil
 }
 
 func (h *HashMap[K, V]) DeleteIf(key K, value V) (bool, error) {
-	//TODO implement me
-	panic("implement me")
+	h.rehashStep()
+	if h.findNode(key) == nil {
+		return false, ErrKeyNotFound
+	}
+	n := h.removeNode(key, func(v V) bool {
+		return reflect.DeepEqual(v, value)
+	})
+	return n != nil, nil
 }
 
 func (h *HashMap[K, V]) Len() int {
-	//TODO implement me
-	panic("implement me")
+	return h.size
+}
+
+func (h *HashMap[K, V]) isRehashing() bool {
+	return h.tables[1] != nil
+}
+
+// each 遍历两个桶数组中的所有节点，不会触发迁移
+func (h *HashMap[K, V]) each(fn func(n *hashNode[K, V])) {
+	for _, table := range h.tables {
+		for _, head := range table {
+			for n := head; n != nil; n = n.next {
+				fn(n)
+			}
+		}
+	}
+}
+
+// findNode 在迁移期间，元素可能在任意一个桶数组中，所以两个都要找
+func (h *HashMap[K, V]) findNode(key K) *hashNode[K, V] {
+	code := key.Code()
+	for _, table := range h.tables {
+		if len(table) == 0 {
+			continue
+		}
+		for n := table[hashIndex(code, len(table))]; n != nil; n = n.next {
+			if key.Equals(n.key) {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// insert 放入一个确定不存在的 key
+// 迁移期间新元素直接放入新的桶数组，这样旧的桶数组只会越来越少
+func (h *HashMap[K, V]) insert(key K, value V) {
+	idx := 0
+	if h.isRehashing() {
+		idx = 1
+	}
+	if h.tables[idx] == nil {
+		// 零值的 HashMap 在这里才分配桶数组
+		h.tables[idx] = make([]*hashNode[K, V], hashMapMinCapacity)
+	}
+	table := h.tables[idx]
+	pos := hashIndex(key.Code(), len(table))
+	table[pos] = &hashNode[K, V]{key: key, value: value, next: table[pos]}
+	h.size++
+	if !h.isRehashing() && h.size > len(h.tables[0]) {
+		h.startRehash(len(h.tables[0]) << 1)
+	}
+}
+
+// removeNode 删除 key 对应且 value 满足 match 的节点，返回被删除的节点
+func (h *HashMap[K, V]) removeNode(key K, match func(v V) bool) *hashNode[K, V] {
+	code := key.Code()
+	for _, table := range h.tables {
+		if len(table) == 0 {
+			continue
+		}
+		pos := hashIndex(code, len(table))
+		var prev *hashNode[K, V]
+		for n := table[pos]; n != nil; prev, n = n, n.next {
+			if !key.Equals(n.key) {
+				continue
+			}
+			if !match(n.value) {
+				return nil
+			}
+			if prev == nil {
+				table[pos] = n.next
+			} else {
+				prev.next = n.next
+			}
+			n.next = nil
+			h.size--
+			h.shrinkIfNecessary()
+			return n
+		}
+	}
+	return nil
+}
+
+// shrinkIfNecessary 元素数量不足容量的 1/8 时，缩容到一半
+func (h *HashMap[K, V]) shrinkIfNecessary() {
+	c := len(h.tables[0])
+	if h.isRehashing() || c <= hashMapMinCapacity || h.size >= c/8 {
+		return
+	}
+	h.startRehash(c >> 1)
+}
+
+func (h *HashMap[K, V]) startRehash(capacity int) {
+	h.tables[1] = make([]*hashNode[K, V], capacity)
+	h.rehashIdx = 0
+}
+
+// rehashStep 迁移最多 hashMapRehashStep 个非空桶
+func (h *HashMap[K, V]) rehashStep() {
+	if !h.isRehashing() {
+		return
+	}
+	src, dst := h.tables[0], h.tables[1]
+	moved, visits := 0, 0
+	for moved < hashMapRehashStep && visits < hashMapMaxEmptyVisits && h.rehashIdx < len(src) {
+		n := src[h.rehashIdx]
+		if n == nil {
+			h.rehashIdx++
+			visits++
+			continue
+		}
+		for n != nil {
+			next := n.next
+			pos := hashIndex(n.key.Code(), len(dst))
+			n.next = dst[pos]
+			dst[pos] = n
+			n = next
+		}
+		src[h.rehashIdx] = nil
+		h.rehashIdx++
+		moved++
+	}
+	if h.rehashIdx >= len(src) {
+		h.tables[0], h.tables[1] = dst, nil
+	}
 }
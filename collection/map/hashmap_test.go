@@ -0,0 +1,294 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMap_Put(t *testing.T) {
+	testCases := []struct {
+		name    string
+		m       func() *HashMap[testKey, int]
+		key     testKey
+		val     int
+		wantOld int
+		wantLen int
+	}{
+		{
+			name: "empty",
+			m: func() *HashMap[testKey, int] {
+				return NewHashMap[testKey, int](0)
+			},
+			key:     1,
+			val:     11,
+			wantLen: 1,
+		},
+		{
+			name: "exist",
+			m: func() *HashMap[testKey, int] {
+				return newHashMapOf(map[testKey]int{1: 11, 2: 12})
+			},
+			key:     1,
+			val:     111,
+			wantOld: 11,
+			wantLen: 2,
+		},
+		{
+			name: "hash conflict",
+			m: func() *HashMap[testKey, int] {
+				return newHashMapOf(map[testKey]int{1: 11})
+			},
+			key:     1 + hashMapMinCapacity,
+			val:     19,
+			wantLen: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.m()
+			old, err := m.Put(tc.key, tc.val)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantOld, old)
+			assert.Equal(t, tc.wantLen, m.Len())
+			val, ok := m.Get(tc.key)
+			assert.True(t, ok)
+			assert.Equal(t, tc.val, val)
+		})
+	}
+}
+
+func TestHashMap_PutIfAbsent(t *testing.T) {
+	m := newHashMapOf(map[testKey]int{1: 11})
+	old, err := m.PutIfAbsent(1, 111)
+	require.NoError(t, err)
+	assert.Equal(t, 11, old)
+	assert.Equal(t, 11, m.GetOrDefault(1, 0))
+
+	old, err = m.PutIfAbsent(2, 12)
+	require.NoError(t, err)
+	assert.Equal(t, 0, old)
+	assert.Equal(t, 12, m.GetOrDefault(2, 0))
+	assert.Equal(t, 2, m.Len())
+}
+
+func TestHashMap_Get(t *testing.T) {
+	m := newHashMapOf(map[testKey]int{1: 11, 9: 19})
+	testCases := []struct {
+		name    string
+		key     testKey
+		wantVal int
+		wantOk  bool
+	}{
+		{
+			name:    "found",
+			key:     1,
+			wantVal: 11,
+			wantOk:  true,
+		},
+		{
+			name:    "found in conflict list",
+			key:     9,
+			wantVal: 19,
+			wantOk:  true,
+		},
+		{
+			name: "not found",
+			key:  17,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			val, ok := m.Get(tc.key)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantVal, val)
+			assert.Equal(t, tc.wantVal, m.GetOrDefault(tc.key, 0))
+		})
+	}
+}
+
+func TestHashMap_Delete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     testKey
+		wantVal int
+		wantErr error
+		wantLen int
+	}{
+		{
+			name:    "head",
+			key:     1,
+			wantVal: 11,
+			wantLen: 2,
+		},
+		{
+			name:    "middle of conflict list",
+			key:     9,
+			wantVal: 19,
+			wantLen: 2,
+		},
+		{
+			name:    "not found",
+			key:     2,
+			wantErr: ErrKeyNotFound,
+			wantLen: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newHashMapOf(map[testKey]int{1: 11, 9: 19, 17: 27})
+			val, err := m.Delete(tc.key)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVal, val)
+			assert.Equal(t, tc.wantLen, m.Len())
+			_, ok := m.Get(tc.key)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestHashMap_DeleteIf(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     testKey
+		val     int
+		wantRes bool
+		wantErr error
+		wantLen int
+	}{
+		{
+			name:    "equal",
+			key:     1,
+			val:     11,
+			wantRes: true,
+			wantLen: 1,
+		},
+		{
+			name:    "not equal",
+			key:     1,
+			val:     12,
+			wantLen: 2,
+		},
+		{
+			name:    "not found",
+			key:     3,
+			val:     13,
+			wantErr: ErrKeyNotFound,
+			wantLen: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newHashMapOf(map[testKey]int{1: 11, 2: 12})
+			res, err := m.DeleteIf(tc.key, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantLen, m.Len())
+		})
+	}
+}
+
+func TestHashMap_KeysValues(t *testing.T) {
+	m := newHashMapOf(map[testKey]int{1: 11, 2: 12, 9: 19})
+	keys, values := m.KeysValues()
+	assert.ElementsMatch(t, []testKey{1, 2, 9}, keys)
+	assert.ElementsMatch(t, []int{11, 12, 19}, values)
+	for i, k := range keys {
+		assert.Equal(t, int(k)+10, values[i])
+	}
+	assert.ElementsMatch(t, keys, m.Keys())
+	assert.ElementsMatch(t, values, m.Values())
+}
+
+// TestHashMap_ZeroValue 零值可以直接使用
+func TestHashMap_ZeroValue(t *testing.T) {
+	var m HashMap[testKey, int]
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+	_, err := m.Delete(1)
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Empty(t, m.Keys())
+	for i := 0; i < 100; i++ {
+		_, err = m.Put(testKey(i), i)
+		require.NoError(t, err)
+	}
+	assertHashMapContains(t, &m, 0, 100)
+}
+
+// TestHashMap_Rehash 在扩容和缩容的迁移过程中，检查每一个元素都不会丢失
+func TestHashMap_Rehash(t *testing.T) {
+	const n = 1000
+	m := NewHashMap[testKey, int](0)
+	rehashed := false
+	for i := 0; i < n; i++ {
+		_, err := m.Put(testKey(i), i)
+		require.NoError(t, err)
+		if m.isRehashing() {
+			rehashed = true
+			assertHashMapContains(t, m, 0, i+1)
+		}
+	}
+	assert.True(t, rehashed)
+	assert.Equal(t, n, m.Len())
+	assertHashMapContains(t, m, 0, n)
+	grown := len(m.tables[0])
+
+	rehashed = false
+	for i := 0; i < n-1; i++ {
+		val, err := m.Delete(testKey(i))
+		require.NoError(t, err)
+		require.Equal(t, i, val)
+		if m.isRehashing() {
+			rehashed = true
+			assertHashMapContains(t, m, i+1, n)
+		}
+	}
+	assert.True(t, rehashed)
+	assert.Equal(t, 1, m.Len())
+	assert.Less(t, len(m.tables[0])+len(m.tables[1]), grown)
+	assertHashMapContains(t, m, n-1, n)
+}
+
+func assertHashMapContains(t *testing.T, m *HashMap[testKey, int], from, to int) {
+	require.Equal(t, to-from, m.Len())
+	require.Len(t, m.Keys(), to-from)
+	for j := from; j < to; j++ {
+		val, ok := m.Get(testKey(j))
+		require.True(t, ok, "key %d", j)
+		require.Equal(t, j, val)
+	}
+}
+
+func newHashMapOf(data map[testKey]int) *HashMap[testKey, int] {
+	m := NewHashMap[testKey, int](len(data))
+	for k, v := range data {
+		_, _ = m.Put(k, v)
+	}
+	return m
+}
+
+type testKey int
+
+func (k testKey) Code() uint64 {
+	return uint64(k)
+}
+
+func (k testKey) Equals(key any) bool {
+	other, ok := key.(testKey)
+	return ok && k == other
+}
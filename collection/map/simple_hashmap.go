@@ -14,56 +14,241 @@
 
 package mapx
 
+import "reflect"
+
+const (
+	defaultMaxLoadFactor   = 0.75
+	defaultShrinkThreshold = 0.125
+)
+
+var _ Map[Hashable, any] = &SimpleHashMap[Hashable, any]{}
+
 // SimpleHashMap 是一个哈希表，采用一次性扩容
+// 扩容或者缩容的时候，会在一次操作中把所有元素迁移到新的桶数组，
+// 和 HashMap 相比实现更简单，但是触发扩缩容的那次操作耗时会明显增加
+// 零值可以直接使用，第一次放入元素的时候才会按照默认配置分配桶数组
 type SimpleHashMap[K Hashable, V any] struct {
+	buckets []*hashNode[K, V]
+	size    int
+	// maxLoadFactor 元素数量超过 容量 * maxLoadFactor 时扩容到两倍
+	maxLoadFactor float64
+	// shrinkThreshold 元素数量低于 容量 * shrinkThreshold 时缩容到一半
+	shrinkThreshold float64
+}
+
+// SimpleHashMapOption 创建 SimpleHashMap 时的配置项，和元素类型无关
+type SimpleHashMapOption func(c *simpleHashMapConfig)
+
+type simpleHashMapConfig struct {
+	initCapacity    int
+	maxLoadFactor   float64
+	shrinkThreshold float64
+}
+
+// NewSimpleHashMap 创建 SimpleHashMap，默认负载因子为 0.75，缩容阈值为 0.125
+func NewSimpleHashMap[K Hashable, V any](opts ...SimpleHashMapOption) *SimpleHashMap[K, V] {
+	c := simpleHashMapConfig{
+		maxLoadFactor:   defaultMaxLoadFactor,
+		shrinkThreshold: defaultShrinkThreshold,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &SimpleHashMap[K, V]{
+		buckets:         make([]*hashNode[K, V], hashCapacity(c.initCapacity)),
+		maxLoadFactor:   c.maxLoadFactor,
+		shrinkThreshold: c.shrinkThreshold,
+	}
+}
+
+// WithInitCapacity 设置初始的桶数量，会向上取整到 2 的幂
+func WithInitCapacity(capacity int) SimpleHashMapOption {
+	return func(c *simpleHashMapConfig) {
+		c.initCapacity = capacity
+	}
+}
+
+// WithMaxLoadFactor 设置最大负载因子，factor <= 0 时忽略
+func WithMaxLoadFactor(factor float64) SimpleHashMapOption {
+	return func(c *simpleHashMapConfig) {
+		if factor > 0 {
+			c.maxLoadFactor = factor
+		}
+	}
+}
+
+// WithShrinkThreshold 设置缩容阈值，threshold <= 0 时表示永不缩容
+// 为了避免在阈值附近反复扩缩容，threshold 应当明显小于 maxLoadFactor 的一半
+func WithShrinkThreshold(threshold float64) SimpleHashMapOption {
+	return func(c *simpleHashMapConfig) {
+		c.shrinkThreshold = threshold
+	}
 }
 
 func (s *SimpleHashMap[K, V]) Keys() []K {
-	//TODO implement me
-	panic("implement me")
+	res := make([]K, 0, s.size)
+	s.each(func(n *hashNode[K, V]) {
+		res = append(res, n.key)
+	})
+	return res
 }
 
 func (s *SimpleHashMap[K, V]) Values() []V {
-	//TODO implement me
-	panic("implement me")
+	res := make([]V, 0, s.size)
+	s.each(func(n *hashNode[K, V]) {
+		res = append(res, n.value)
+	})
+	return res
 }
 
-func (s *SimpleHashMap[K, V]) KeysValues() []V {
-	//TODO implement me
-	panic("implement me")
+func (s *SimpleHashMap[K, V]) KeysValues() ([]K, []V) {
+	keys := make([]K, 0, s.size)
+	values := make([]V, 0, s.size)
+	s.each(func(n *hashNode[K, V]) {
+		keys = append(keys, n.key)
+		values = append(values, n.value)
+	})
+	return keys, values
 }
 
 func (s *SimpleHashMap[K, V]) Get(key K) (V, bool) {
-	//TODO implement me
-	panic("implement me")
+	if n := s.findNode(key); n != nil {
+		return n.value, true
+	}
+	var v V
+	return v, false
 }
 
 func (s *SimpleHashMap[K, V]) GetOrDefault(key K, value V) V {
-	//TODO implement me
-	panic("implement me")
+	if v, ok := s.Get(key); ok {
+		return v
+	}
+	return value
 }
 
 func (s *SimpleHashMap[K, V]) Put(key K, value V) (V, error) {
-	//TODO implement me
-	panic("implement me")
+	if n := s.findNode(key); n != nil {
+		old := n.value
+		n.value = value
+		return old, nil
+	}
+	s.insert(key, value)
+	var v V
+	return v, nil
 }
 
 func (s *SimpleHashMap[K, V]) PutIfAbsent(key K, value V) (V, error) {
-	//TODO implement me
-	panic("implement me")
+	if n := s.findNode(key); n != nil {
+		return n.value, nil
+	}
+	s.insert(key, value)
+	var v V
+	return v, nil
 }
 
 func (s *SimpleHashMap[K, V]) Delete(key K) (V, error) {
-	//TODO implement me
-	panic("implement me")
+	n := s.removeNode(key, func(V) bool { return true })
+	if n == nil {
+		var v V
+		return v, ErrKeyNotFound
+	}
+	return n.value, nil
 }
 
 func (s *SimpleHashMap[K, V]) DeleteIf(key K, value V) (bool, error) {
-	//TODO implement me
-	panic("implement me")
+	if s.findNode(key) == nil {
+		return false, ErrKeyNotFound
+	}
+	n := s.removeNode(key, func(v V) bool {
+		return reflect.DeepEqual(v, value)
+	})
+	return n != nil, nil
 }
 
 func (s *SimpleHashMap[K, V]) Len() int {
-	//TODO implement me
-	panic("implement me")
+	return s.size
+}
+
+func (s *SimpleHashMap[K, V]) each(fn func(n *hashNode[K, V])) {
+	for _, head := range s.buckets {
+		for n := head; n != nil; n = n.next {
+			fn(n)
+		}
+	}
+}
+
+func (s *SimpleHashMap[K, V]) findNode(key K) *hashNode[K, V] {
+	if len(s.buckets) == 0 {
+		return nil
+	}
+	for n := s.buckets[hashIndex(key.Code(), len(s.buckets))]; n != nil; n = n.next {
+		if key.Equals(n.key) {
+			return n
+		}
+	}
+	return nil
+}
+
+func (s *SimpleHashMap[K, V]) insert(key K, value V) {
+	if s.buckets == nil {
+		// 零值没有经过 NewSimpleHashMap，使用默认配置
+		s.buckets = make([]*hashNode[K, V], hashMapMinCapacity)
+		s.maxLoadFactor = defaultMaxLoadFactor
+		s.shrinkThreshold = defaultShrinkThreshold
+	}
+	pos := hashIndex(key.Code(), len(s.buckets))
+	s.buckets[pos] = &hashNode[K, V]{key: key, value: value, next: s.buckets[pos]}
+	s.size++
+	if float64(s.size) > float64(len(s.buckets))*s.maxLoadFactor {
+		s.resize(len(s.buckets) << 1)
+	}
+}
+
+func (s *SimpleHashMap[K, V]) removeNode(key K, match func(v V) bool) *hashNode[K, V] {
+	if len(s.buckets) == 0 {
+		return nil
+	}
+	pos := hashIndex(key.Code(), len(s.buckets))
+	var prev *hashNode[K, V]
+	for n := s.buckets[pos]; n != nil; prev, n = n, n.next {
+		if !key.Equals(n.key) {
+			continue
+		}
+		if !match(n.value) {
+			return nil
+		}
+		if prev == nil {
+			s.buckets[pos] = n.next
+		} else {
+			prev.next = n.next
+		}
+		n.next = nil
+		s.size--
+		s.shrinkIfNecessary()
+		return n
+	}
+	return nil
+}
+
+func (s *SimpleHashMap[K, V]) shrinkIfNecessary() {
+	c := len(s.buckets)
+	if c <= hashMapMinCapacity || float64(s.size) >= float64(c)*s.shrinkThreshold {
+		return
+	}
+	s.resize(c >> 1)
+}
+
+// resize 一次性把所有元素迁移到新的桶数组
+func (s *SimpleHashMap[K, V]) resize(capacity int) {
+	buckets := make([]*hashNode[K, V], capacity)
+	for _, n := range s.buckets {
+		for n != nil {
+			next := n.next
+			pos := hashIndex(n.key.Code(), capacity)
+			n.next = buckets[pos]
+			buckets[pos] = n
+			n = next
+		}
+	}
+	s.buckets = buckets
 }
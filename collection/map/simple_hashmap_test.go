@@ -0,0 +1,286 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSimpleHashMap(t *testing.T) {
+	testCases := []struct {
+		name                string
+		opts                []SimpleHashMapOption
+		wantCap             int
+		wantMaxLoadFactor   float64
+		wantShrinkThreshold float64
+	}{
+		{
+			name:                "default",
+			wantCap:             hashMapMinCapacity,
+			wantMaxLoadFactor:   defaultMaxLoadFactor,
+			wantShrinkThreshold: defaultShrinkThreshold,
+		},
+		{
+			name: "options",
+			opts: []SimpleHashMapOption{
+				WithInitCapacity(100),
+				WithMaxLoadFactor(2),
+				WithShrinkThreshold(0.25),
+			},
+			wantCap:             128,
+			wantMaxLoadFactor:   2,
+			wantShrinkThreshold: 0.25,
+		},
+		{
+			name: "invalid load factor",
+			opts: []SimpleHashMapOption{
+				WithMaxLoadFactor(0),
+			},
+			wantCap:             hashMapMinCapacity,
+			wantMaxLoadFactor:   defaultMaxLoadFactor,
+			wantShrinkThreshold: defaultShrinkThreshold,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewSimpleHashMap[testKey, int](tc.opts...)
+			assert.Equal(t, tc.wantCap, len(m.buckets))
+			assert.Equal(t, tc.wantMaxLoadFactor, m.maxLoadFactor)
+			assert.Equal(t, tc.wantShrinkThreshold, m.shrinkThreshold)
+			assert.Equal(t, 0, m.Len())
+		})
+	}
+}
+
+func TestSimpleHashMap_Put(t *testing.T) {
+	m := NewSimpleHashMap[testKey, int]()
+	old, err := m.Put(1, 11)
+	require.NoError(t, err)
+	assert.Equal(t, 0, old)
+	old, err = m.Put(1, 111)
+	require.NoError(t, err)
+	assert.Equal(t, 11, old)
+	old, err = m.PutIfAbsent(1, 1111)
+	require.NoError(t, err)
+	assert.Equal(t, 111, old)
+	old, err = m.PutIfAbsent(9, 19)
+	require.NoError(t, err)
+	assert.Equal(t, 0, old)
+
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, 111, m.GetOrDefault(1, 0))
+	assert.Equal(t, 19, m.GetOrDefault(9, 0))
+	assert.Equal(t, -1, m.GetOrDefault(17, -1))
+}
+
+func TestSimpleHashMap_Delete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     testKey
+		wantVal int
+		wantErr error
+		wantLen int
+	}{
+		{
+			name:    "head",
+			key:     1,
+			wantVal: 11,
+			wantLen: 2,
+		},
+		{
+			name:    "middle of conflict list",
+			key:     9,
+			wantVal: 19,
+			wantLen: 2,
+		},
+		{
+			name:    "not found",
+			key:     2,
+			wantErr: ErrKeyNotFound,
+			wantLen: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newSimpleHashMapOf(map[testKey]int{1: 11, 9: 19, 17: 27})
+			val, err := m.Delete(tc.key)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVal, val)
+			assert.Equal(t, tc.wantLen, m.Len())
+			_, ok := m.Get(tc.key)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestSimpleHashMap_DeleteIf(t *testing.T) {
+	m := newSimpleHashMapOf(map[testKey]int{1: 11, 2: 12})
+	res, err := m.DeleteIf(1, 12)
+	require.NoError(t, err)
+	assert.False(t, res)
+	res, err = m.DeleteIf(1, 11)
+	require.NoError(t, err)
+	assert.True(t, res)
+	_, err = m.DeleteIf(1, 11)
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Equal(t, 1, m.Len())
+
+	keys, values := m.KeysValues()
+	assert.Equal(t, []testKey{2}, keys)
+	assert.Equal(t, []int{12}, values)
+	assert.Equal(t, keys, m.Keys())
+	assert.Equal(t, values, m.Values())
+}
+
+func TestSimpleHashMap_Resize(t *testing.T) {
+	testCases := []struct {
+		name          string
+		opts          []SimpleHashMapOption
+		put           int
+		wantGrowCap   int
+		remain        int
+		wantShrinkCap int
+	}{
+		{
+			name:          "default",
+			put:           100,
+			wantGrowCap:   256,
+			remain:        1,
+			wantShrinkCap: hashMapMinCapacity,
+		},
+		{
+			name: "load factor 2",
+			opts: []SimpleHashMapOption{
+				WithMaxLoadFactor(2),
+			},
+			put:           100,
+			wantGrowCap:   64,
+			remain:        10,
+			wantShrinkCap: 64,
+		},
+		{
+			name: "never shrink",
+			opts: []SimpleHashMapOption{
+				WithShrinkThreshold(0),
+			},
+			put:           100,
+			wantGrowCap:   256,
+			remain:        0,
+			wantShrinkCap: 256,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewSimpleHashMap[testKey, int](tc.opts...)
+			for i := 0; i < tc.put; i++ {
+				_, err := m.Put(testKey(i), i)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantGrowCap, len(m.buckets))
+			for i := 0; i < tc.put; i++ {
+				require.Equal(t, i, m.GetOrDefault(testKey(i), -1))
+			}
+			for i := tc.remain; i < tc.put; i++ {
+				_, err := m.Delete(testKey(i))
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantShrinkCap, len(m.buckets))
+			assert.Equal(t, tc.remain, m.Len())
+			for i := 0; i < tc.remain; i++ {
+				require.Equal(t, i, m.GetOrDefault(testKey(i), -1))
+			}
+		})
+	}
+}
+
+// TestSimpleHashMap_ZeroValue 零值可以直接使用，并且按照默认配置扩缩容
+func TestSimpleHashMap_ZeroValue(t *testing.T) {
+	var m SimpleHashMap[testKey, int]
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+	_, err := m.Delete(1)
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Empty(t, m.Keys())
+	for i := 0; i < 100; i++ {
+		_, err = m.Put(testKey(i), i)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 100, m.Len())
+	assert.Equal(t, 256, len(m.buckets))
+	for i := 0; i < 100; i++ {
+		val, ok := m.Get(testKey(i))
+		require.True(t, ok)
+		require.Equal(t, i, val)
+	}
+}
+
+func BenchmarkHashMap_Put(b *testing.B) {
+	b.Run("HashMap", func(b *testing.B) {
+		m := NewHashMap[testKey, int](0)
+		for i := 0; i < b.N; i++ {
+			_, _ = m.Put(testKey(i), i)
+		}
+	})
+	b.Run("SimpleHashMap", func(b *testing.B) {
+		m := NewSimpleHashMap[testKey, int]()
+		for i := 0; i < b.N; i++ {
+			_, _ = m.Put(testKey(i), i)
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		m := make(map[testKey]int)
+		for i := 0; i < b.N; i++ {
+			m[testKey(i)] = i
+		}
+	})
+}
+
+func BenchmarkHashMap_Get(b *testing.B) {
+	const n = 1 << 16
+	hm := NewHashMap[testKey, int](0)
+	sm := NewSimpleHashMap[testKey, int]()
+	m := make(map[testKey]int)
+	for i := 0; i < n; i++ {
+		_, _ = hm.Put(testKey(i), i)
+		_, _ = sm.Put(testKey(i), i)
+		m[testKey(i)] = i
+	}
+	b.Run("HashMap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = hm.Get(testKey(i % n))
+		}
+	})
+	b.Run("SimpleHashMap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = sm.Get(testKey(i % n))
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = m[testKey(i%n)]
+		}
+	})
+}
+
+func newSimpleHashMapOf(data map[testKey]int) *SimpleHashMap[testKey, int] {
+	m := NewSimpleHashMap[testKey, int](WithInitCapacity(len(data)))
+	for k, v := range data {
+		_, _ = m.Put(k, v)
+	}
+	return m
+}
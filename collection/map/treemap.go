@@ -0,0 +1,315 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"reflect"
+
+	"github.com/igevin/algokit/collection/tree/redblacktree"
+	"github.com/igevin/algokit/comparator"
+)
+
+var _ Map[int, any] = &TreeMap[int, any]{}
+
+// TreeMap 是基于红黑树的有序 map，key 的顺序由 compare 决定
+// HeadMap、TailMap、SubMap 返回的是视图：它们和原 TreeMap 共享同一棵树，
+// 对视图的修改会反映到原 TreeMap 上，反之亦然；视图只能看到和修改范围内的 key
+type TreeMap[K any, V any] struct {
+	tree    *redblacktree.RBTree[K, V]
+	compare comparator.Compare[K]
+	// lo 和 hi 是视图的上下界，没有设置的时候表示不限制
+	lo, hi treeBound[K]
+}
+
+type treeBound[K any] struct {
+	key       K
+	inclusive bool
+	set       bool
+}
+
+// NewTreeMap 创建 TreeMap
+func NewTreeMap[K any, V any](compare comparator.Compare[K]) *TreeMap[K, V] {
+	return &TreeMap[K, V]{
+		tree:    redblacktree.NewRBTree[K, V](compare),
+		compare: compare,
+	}
+}
+
+func (t *TreeMap[K, V]) Keys() []K {
+	res := make([]K, 0, t.tree.Size())
+	t.each(func(k K, _ V) {
+		res = append(res, k)
+	})
+	return res
+}
+
+func (t *TreeMap[K, V]) Values() []V {
+	res := make([]V, 0, t.tree.Size())
+	t.each(func(_ K, v V) {
+		res = append(res, v)
+	})
+	return res
+}
+
+// KeysValues 按照 key 升序返回所有的键值对
+func (t *TreeMap[K, V]) KeysValues() ([]K, []V) {
+	keys := make([]K, 0, t.tree.Size())
+	values := make([]V, 0, t.tree.Size())
+	t.each(func(k K, v V) {
+		keys = append(keys, k)
+		values = append(values, v)
+	})
+	return keys, values
+}
+
+func (t *TreeMap[K, V]) Get(key K) (V, bool) {
+	if !t.inRange(key) {
+		var v V
+		return v, false
+	}
+	v, err := t.tree.Find(key)
+	return v, err == nil
+}
+
+func (t *TreeMap[K, V]) GetOrDefault(key K, value V) V {
+	if v, ok := t.Get(key); ok {
+		return v
+	}
+	return value
+}
+
+// Put 如果 key 不在视图范围内，返回 ErrKeyOutOfRange
+func (t *TreeMap[K, V]) Put(key K, value V) (V, error) {
+	var zero V
+	if !t.inRange(key) {
+		return zero, ErrKeyOutOfRange
+	}
+	if old, err := t.tree.Find(key); err == nil {
+		return old, t.tree.Set(key, value)
+	}
+	return zero, t.tree.Add(key, value)
+}
+
+// PutIfAbsent 如果 key 不在视图范围内，返回 ErrKeyOutOfRange
+func (t *TreeMap[K, V]) PutIfAbsent(key K, value V) (V, error) {
+	var zero V
+	if !t.inRange(key) {
+		return zero, ErrKeyOutOfRange
+	}
+	if old, err := t.tree.Find(key); err == nil {
+		return old, nil
+	}
+	return zero, t.tree.Add(key, value)
+}
+
+func (t *TreeMap[K, V]) Delete(key K) (V, error) {
+	old, ok := t.Get(key)
+	if !ok {
+		return old, ErrKeyNotFound
+	}
+	return old, t.tree.Delete(key)
+}
+
+func (t *TreeMap[K, V]) DeleteIf(key K, value V) (bool, error) {
+	old, ok := t.Get(key)
+	if !ok {
+		return false, ErrKeyNotFound
+	}
+	if !reflect.DeepEqual(old, value) {
+		return false, nil
+	}
+	return true, t.tree.Delete(key)
+}
+
+// Len 对于视图来说，借助红黑树的顺序统计计算范围内的 key 数量，时间复杂度是 O(log n)
+func (t *TreeMap[K, V]) Len() int {
+	if !t.lo.set && !t.hi.set {
+		return t.tree.Size()
+	}
+	first, _, ok := t.First()
+	if !ok {
+		return 0
+	}
+	last, _, ok := t.Last()
+	if !ok || t.compare(first, last) > 0 {
+		return 0
+	}
+	return t.tree.CountRange(first, last) + 1
+}
+
+// First 返回最小的键值对
+func (t *TreeMap[K, V]) First() (K, V, bool) {
+	if !t.lo.set {
+		return t.result(t.tree.First())
+	}
+	if t.lo.inclusive {
+		return t.result(t.tree.Ceiling(t.lo.key))
+	}
+	return t.result(t.tree.Higher(t.lo.key))
+}
+
+// Last 返回最大的键值对
+func (t *TreeMap[K, V]) Last() (K, V, bool) {
+	if !t.hi.set {
+		return t.result(t.tree.Last())
+	}
+	if t.hi.inclusive {
+		return t.result(t.tree.Floor(t.hi.key))
+	}
+	return t.result(t.tree.Lower(t.hi.key))
+}
+
+// Floor 返回小于等于 key 的最大键值对
+func (t *TreeMap[K, V]) Floor(key K) (K, V, bool) {
+	if t.tooHigh(key) {
+		return t.Last()
+	}
+	return t.result(t.tree.Floor(key))
+}
+
+// Ceiling 返回大于等于 key 的最小键值对
+func (t *TreeMap[K, V]) Ceiling(key K) (K, V, bool) {
+	if t.tooLow(key) {
+		return t.First()
+	}
+	return t.result(t.tree.Ceiling(key))
+}
+
+// Lower 返回严格小于 key 的最大键值对
+func (t *TreeMap[K, V]) Lower(key K) (K, V, bool) {
+	if t.tooHigh(key) {
+		return t.Last()
+	}
+	return t.result(t.tree.Lower(key))
+}
+
+// Higher 返回严格大于 key 的最小键值对
+func (t *TreeMap[K, V]) Higher(key K) (K, V, bool) {
+	if t.tooLow(key) {
+		return t.First()
+	}
+	return t.result(t.tree.Higher(key))
+}
+
+// PollFirst 删除并返回最小的键值对
+func (t *TreeMap[K, V]) PollFirst() (K, V, bool) {
+	k, v, ok := t.First()
+	if ok {
+		_ = t.tree.Delete(k)
+	}
+	return k, v, ok
+}
+
+// PollLast 删除并返回最大的键值对
+func (t *TreeMap[K, V]) PollLast() (K, V, bool) {
+	k, v, ok := t.Last()
+	if ok {
+		_ = t.tree.Delete(k)
+	}
+	return k, v, ok
+}
+
+// HeadMap 返回 key 小于 to 的视图，inclusive 为 true 时包含 to
+func (t *TreeMap[K, V]) HeadMap(to K, inclusive ...bool) *TreeMap[K, V] {
+	res := *t
+	res.narrowHigh(to, len(inclusive) > 0 && inclusive[0])
+	return &res
+}
+
+// TailMap 返回 key 大于等于 from 的视图，inclusive 为 false 时不包含 from
+func (t *TreeMap[K, V]) TailMap(from K, inclusive ...bool) *TreeMap[K, V] {
+	res := *t
+	res.narrowLow(from, len(inclusive) == 0 || inclusive[0])
+	return &res
+}
+
+// SubMap 返回 key 在 [from, to) 之间的视图
+// inclusive[0] 控制是否包含 from，默认包含；inclusive[1] 控制是否包含 to，默认不包含
+// 如果当前已经是一个视图，那么结果是两个范围的交集
+func (t *TreeMap[K, V]) SubMap(from, to K, inclusive ...bool) *TreeMap[K, V] {
+	res := *t
+	res.narrowLow(from, len(inclusive) < 1 || inclusive[0])
+	res.narrowHigh(to, len(inclusive) > 1 && inclusive[1])
+	return &res
+}
+
+// narrowLow 收紧下界，只有新的下界比原来的更严格时才会生效
+func (t *TreeMap[K, V]) narrowLow(key K, inclusive bool) {
+	if t.lo.set {
+		cmp := t.compare(key, t.lo.key)
+		if cmp < 0 || (cmp == 0 && (inclusive || !t.lo.inclusive)) {
+			return
+		}
+	}
+	t.lo = treeBound[K]{key: key, inclusive: inclusive, set: true}
+}
+
+// narrowHigh 收紧上界，只有新的上界比原来的更严格时才会生效
+func (t *TreeMap[K, V]) narrowHigh(key K, inclusive bool) {
+	if t.hi.set {
+		cmp := t.compare(key, t.hi.key)
+		if cmp > 0 || (cmp == 0 && (inclusive || !t.hi.inclusive)) {
+			return
+		}
+	}
+	t.hi = treeBound[K]{key: key, inclusive: inclusive, set: true}
+}
+
+func (t *TreeMap[K, V]) tooLow(key K) bool {
+	if !t.lo.set {
+		return false
+	}
+	cmp := t.compare(key, t.lo.key)
+	return cmp < 0 || (cmp == 0 && !t.lo.inclusive)
+}
+
+func (t *TreeMap[K, V]) tooHigh(key K) bool {
+	if !t.hi.set {
+		return false
+	}
+	cmp := t.compare(key, t.hi.key)
+	return cmp > 0 || (cmp == 0 && !t.hi.inclusive)
+}
+
+func (t *TreeMap[K, V]) inRange(key K) bool {
+	return !t.tooLow(key) && !t.tooHigh(key)
+}
+
+// result 把红黑树的查询结果转换为视图范围内的结果
+func (t *TreeMap[K, V]) result(k K, v V, err error) (K, V, bool) {
+	if err != nil || !t.inRange(k) {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return k, v, true
+}
+
+// each 按照 key 升序遍历视图范围内的键值对
+// 先确定范围内的第一个和最后一个 key，再用 [first, last) 的迭代器加上 last 本身覆盖整个范围
+func (t *TreeMap[K, V]) each(fn func(k K, v V)) {
+	first, _, ok := t.First()
+	if !ok {
+		return
+	}
+	last, lastVal, ok := t.Last()
+	if !ok || t.compare(first, last) > 0 {
+		return
+	}
+	for k, v := range t.tree.Range(first, last) {
+		fn(k, v)
+	}
+	fn(last, lastVal)
+}
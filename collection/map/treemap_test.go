@@ -0,0 +1,259 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeMap_Put(t *testing.T) {
+	m := NewTreeMap[int, string](comparator.PrimeComparator[int])
+	old, err := m.Put(2, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "", old)
+	old, err = m.Put(2, "bb")
+	require.NoError(t, err)
+	assert.Equal(t, "b", old)
+	old, err = m.PutIfAbsent(2, "bbb")
+	require.NoError(t, err)
+	assert.Equal(t, "bb", old)
+	old, err = m.PutIfAbsent(1, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "", old)
+
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, "bb", m.GetOrDefault(2, ""))
+	assert.Equal(t, "a", m.GetOrDefault(1, ""))
+	assert.Equal(t, "z", m.GetOrDefault(3, "z"))
+	keys, values := m.KeysValues()
+	assert.Equal(t, []int{1, 2}, keys)
+	assert.Equal(t, []string{"a", "bb"}, values)
+}
+
+func TestTreeMap_Delete(t *testing.T) {
+	m := newTreeMapOf(1, 2, 3)
+	val, err := m.Delete(2)
+	require.NoError(t, err)
+	assert.Equal(t, 20, val)
+	_, err = m.Delete(2)
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	ok, err := m.DeleteIf(1, 11)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = m.DeleteIf(1, 10)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, err = m.DeleteIf(1, 10)
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Equal(t, []int{3}, m.Keys())
+	assert.Equal(t, []int{30}, m.Values())
+}
+
+func TestTreeMap_Navigation(t *testing.T) {
+	m := newTreeMapOf(10, 20, 30, 40, 50)
+	testCases := []struct {
+		name    string
+		nav     func(key int) (int, int, bool)
+		key     int
+		wantKey int
+		wantOk  bool
+	}{
+		{name: "floor exist", nav: m.Floor, key: 30, wantKey: 30, wantOk: true},
+		{name: "floor between", nav: m.Floor, key: 35, wantKey: 30, wantOk: true},
+		{name: "floor none", nav: m.Floor, key: 5},
+		{name: "ceiling exist", nav: m.Ceiling, key: 30, wantKey: 30, wantOk: true},
+		{name: "ceiling between", nav: m.Ceiling, key: 35, wantKey: 40, wantOk: true},
+		{name: "ceiling none", nav: m.Ceiling, key: 55},
+		{name: "lower", nav: m.Lower, key: 30, wantKey: 20, wantOk: true},
+		{name: "lower none", nav: m.Lower, key: 10},
+		{name: "higher", nav: m.Higher, key: 30, wantKey: 40, wantOk: true},
+		{name: "higher none", nav: m.Higher, key: 50},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			k, v, ok := tc.nav(tc.key)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantKey, k)
+			assert.Equal(t, tc.wantKey*10, v)
+		})
+	}
+}
+
+func TestTreeMap_Poll(t *testing.T) {
+	m := newTreeMapOf(3, 1, 2)
+	k, v, ok := m.PollFirst()
+	assert.True(t, ok)
+	assert.Equal(t, 1, k)
+	assert.Equal(t, 10, v)
+	k, _, ok = m.PollLast()
+	assert.True(t, ok)
+	assert.Equal(t, 3, k)
+	k, _, ok = m.PollLast()
+	assert.True(t, ok)
+	assert.Equal(t, 2, k)
+	_, _, ok = m.PollFirst()
+	assert.False(t, ok)
+	_, _, ok = m.PollLast()
+	assert.False(t, ok)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestTreeMap_Views(t *testing.T) {
+	testCases := []struct {
+		name      string
+		view      func(m *TreeMap[int, int]) *TreeMap[int, int]
+		wantKeys  []int
+		wantFirst int
+		wantLast  int
+	}{
+		{
+			name: "head exclusive",
+			view: func(m *TreeMap[int, int]) *TreeMap[int, int] {
+				return m.HeadMap(30)
+			},
+			wantKeys:  []int{10, 20},
+			wantFirst: 10,
+			wantLast:  20,
+		},
+		{
+			name: "head inclusive",
+			view: func(m *TreeMap[int, int]) *TreeMap[int, int] {
+				return m.HeadMap(30, true)
+			},
+			wantKeys:  []int{10, 20, 30},
+			wantFirst: 10,
+			wantLast:  30,
+		},
+		{
+			name: "tail inclusive",
+			view: func(m *TreeMap[int, int]) *TreeMap[int, int] {
+				return m.TailMap(30)
+			},
+			wantKeys:  []int{30, 40, 50},
+			wantFirst: 30,
+			wantLast:  50,
+		},
+		{
+			name: "tail exclusive",
+			view: func(m *TreeMap[int, int]) *TreeMap[int, int] {
+				return m.TailMap(30, false)
+			},
+			wantKeys:  []int{40, 50},
+			wantFirst: 40,
+			wantLast:  50,
+		},
+		{
+			name: "sub default",
+			view: func(m *TreeMap[int, int]) *TreeMap[int, int] {
+				return m.SubMap(20, 40)
+			},
+			wantKeys:  []int{20, 30},
+			wantFirst: 20,
+			wantLast:  30,
+		},
+		{
+			name: "sub both exclusive",
+			view: func(m *TreeMap[int, int]) *TreeMap[int, int] {
+				return m.SubMap(20, 40, false, false)
+			},
+			wantKeys:  []int{30},
+			wantFirst: 30,
+			wantLast:  30,
+		},
+		{
+			name: "sub both inclusive",
+			view: func(m *TreeMap[int, int]) *TreeMap[int, int] {
+				return m.SubMap(15, 40, true, true)
+			},
+			wantKeys:  []int{20, 30, 40},
+			wantFirst: 20,
+			wantLast:  40,
+		},
+		{
+			name: "nested views intersect",
+			view: func(m *TreeMap[int, int]) *TreeMap[int, int] {
+				return m.HeadMap(40, true).TailMap(20, false).SubMap(0, 100)
+			},
+			wantKeys:  []int{30, 40},
+			wantFirst: 30,
+			wantLast:  40,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTreeMapOf(10, 20, 30, 40, 50)
+			view := tc.view(m)
+			assert.Equal(t, tc.wantKeys, view.Keys())
+			assert.Equal(t, len(tc.wantKeys), view.Len())
+			k, _, ok := view.First()
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantFirst, k)
+			k, _, ok = view.Last()
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantLast, k)
+			k, _, ok = view.Floor(1000)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantLast, k)
+			k, _, ok = view.Ceiling(-1000)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantFirst, k)
+			_, ok = view.Get(10)
+			assert.Equal(t, tc.wantFirst == 10, ok)
+		})
+	}
+}
+
+func TestTreeMap_ViewWriteThrough(t *testing.T) {
+	m := newTreeMapOf(10, 20, 30, 40, 50)
+	view := m.SubMap(20, 40)
+
+	_, err := view.Put(45, 450)
+	assert.Equal(t, ErrKeyOutOfRange, err)
+	_, err = view.PutIfAbsent(40, 400)
+	assert.Equal(t, ErrKeyOutOfRange, err)
+	_, err = view.Delete(10)
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	_, err = view.Put(25, 250)
+	require.NoError(t, err)
+	assert.Equal(t, 250, m.GetOrDefault(25, 0))
+
+	_, err = m.Put(35, 350)
+	require.NoError(t, err)
+	assert.Equal(t, []int{20, 25, 30, 35}, view.Keys())
+
+	k, _, ok := view.PollLast()
+	assert.True(t, ok)
+	assert.Equal(t, 35, k)
+	k, _, ok = view.PollFirst()
+	assert.True(t, ok)
+	assert.Equal(t, 20, k)
+	assert.Equal(t, []int{10, 25, 30, 40, 50}, m.Keys())
+	assert.Equal(t, 2, view.Len())
+}
+
+// newTreeMapOf 构造 TreeMap，value 是 key 的 10 倍
+func newTreeMapOf(keys ...int) *TreeMap[int, int] {
+	m := NewTreeMap[int, int](comparator.PrimeComparator[int])
+	for _, k := range keys {
+		_, _ = m.Put(k, k*10)
+	}
+	return m
+}
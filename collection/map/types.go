@@ -17,15 +17,19 @@ package mapx
 type mapx[K any, V any] interface {
 	Keys() []K
 	Values() []V
-	KeysValues() []V
+	// KeysValues 返回所有的 key 和 value，两者的相对顺序是一致的
+	KeysValues() ([]K, []V)
 }
 
 type Map[K any, V any] interface {
 	mapx[K, V]
 	Get(key K) (V, bool)
 	GetOrDefault(key K, value V) V
+	// Put 放入键值对，返回原来的值；如果原本不存在，返回零值
 	Put(key K, value V) (V, error)
+	// PutIfAbsent 只有在 key 不存在的时候才放入，返回原来的值；如果原本不存在，返回零值
 	PutIfAbsent(key K, value V) (V, error)
+	// Delete 删除 key，返回被删除的值；如果 key 不存在，返回 ErrKeyNotFound
 	Delete(key K) (V, error)
 	// DeleteIf delete if Map[key]== value
 	DeleteIf(key K, value V) (bool, error)
@@ -17,3 +17,8 @@ package queue
 import "errors"
 
 var ErrDequeueFromEmptyQueue = errors.New("algokit: delete form empty queue")
+
+var (
+	ErrIDExists   = errors.New("algokit: 队列中已经存在该 id")
+	ErrIDNotFound = errors.New("algokit: 队列中不存在该 id")
+)
@@ -0,0 +1,198 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"github.com/igevin/algokit/comparator"
+	"github.com/igevin/algokit/internal/slice"
+)
+
+// IndexedPriorityQueue 带索引的优先队列，基于小顶堆
+// 每个元素都有一个唯一的 id，通过 id 可以在 O(log n) 内修改元素的优先级或者删除元素，
+// 适用于 Dijkstra、Prim 这类需要 DecreaseKey 的算法，或者任务的重新排序
+// 当capacity <= 0时，为无界队列，切片容量会动态扩缩容
+// 当capacity > 0 时，为有界队列，初始化后就固定容量，不会扩缩容
+type IndexedPriorityQueue[K comparable, T any] struct {
+	comparator comparator.Compare[T]
+	capacity   int
+	// data 堆中的元素，根节点在 0 位置
+	data []indexedItem[K, T]
+	// index 记录 id 在 data 中的下标
+	index map[K]int
+}
+
+type indexedItem[K comparable, T any] struct {
+	id  K
+	val T
+}
+
+// NewIndexedPriorityQueue 创建带索引的优先队列 capacity <= 0 时，为无界队列，否则有有界队列
+func NewIndexedPriorityQueue[K comparable, T any](capacity int, compare comparator.Compare[T]) *IndexedPriorityQueue[K, T] {
+	sliceCap := capacity
+	if capacity < 1 {
+		capacity = 0
+		sliceCap = 64
+	}
+	return &IndexedPriorityQueue[K, T]{
+		comparator: compare,
+		capacity:   capacity,
+		data:       make([]indexedItem[K, T], 0, sliceCap),
+		index:      make(map[K]int, sliceCap),
+	}
+}
+
+func (p *IndexedPriorityQueue[K, T]) Len() int {
+	return len(p.data)
+}
+
+// Cap 无界队列返回0，有界队列返回创建队列时设置的值
+func (p *IndexedPriorityQueue[K, T]) Cap() int {
+	return p.capacity
+}
+
+func (p *IndexedPriorityQueue[K, T]) IsBoundless() bool {
+	return p.capacity <= 0
+}
+
+// Contains 判断队列中是否存在 id
+func (p *IndexedPriorityQueue[K, T]) Contains(id K) bool {
+	_, ok := p.index[id]
+	return ok
+}
+
+// Get 返回 id 对应的元素
+func (p *IndexedPriorityQueue[K, T]) Get(id K) (T, bool) {
+	i, ok := p.index[id]
+	if !ok {
+		var t T
+		return t, false
+	}
+	return p.data[i].val, true
+}
+
+// Peek 返回堆顶的元素和它的 id，但是不会取出
+func (p *IndexedPriorityQueue[K, T]) Peek() (K, T, error) {
+	if len(p.data) == 0 {
+		var id K
+		var t T
+		return id, t, ErrEmptyQueue
+	}
+	return p.data[0].id, p.data[0].val, nil
+}
+
+// Enqueue 放入元素，id 已经存在的时候返回 ErrIDExists
+func (p *IndexedPriorityQueue[K, T]) Enqueue(id K, t T) error {
+	if _, ok := p.index[id]; ok {
+		return ErrIDExists
+	}
+	if p.capacity > 0 && len(p.data) >= p.capacity {
+		return ErrOutOfCapacity
+	}
+	p.data = append(p.data, indexedItem[K, T]{id: id, val: t})
+	p.index[id] = len(p.data) - 1
+	p.up(len(p.data) - 1)
+	return nil
+}
+
+// Dequeue 取出堆顶的元素和它的 id
+func (p *IndexedPriorityQueue[K, T]) Dequeue() (K, T, error) {
+	if len(p.data) == 0 {
+		var id K
+		var t T
+		return id, t, ErrEmptyQueue
+	}
+	item := p.removeAt(0)
+	return item.id, item.val, nil
+}
+
+// Update 用 t 替换 id 对应的元素，并且根据新的优先级调整位置
+// 优先级变高或者变低都可以，id 不存在的时候返回 ErrIDNotFound
+func (p *IndexedPriorityQueue[K, T]) Update(id K, t T) error {
+	i, ok := p.index[id]
+	if !ok {
+		return ErrIDNotFound
+	}
+	p.data[i].val = t
+	if !p.down(i) {
+		p.up(i)
+	}
+	return nil
+}
+
+// Remove 删除 id 对应的元素，并且返回它，id 不存在的时候返回 ErrIDNotFound
+func (p *IndexedPriorityQueue[K, T]) Remove(id K) (T, error) {
+	i, ok := p.index[id]
+	if !ok {
+		var t T
+		return t, ErrIDNotFound
+	}
+	return p.removeAt(i).val, nil
+}
+
+// removeAt 删除下标为 i 的元素，用最后一个元素填补空位之后再调整
+func (p *IndexedPriorityQueue[K, T]) removeAt(i int) indexedItem[K, T] {
+	n := len(p.data) - 1
+	item := p.data[i]
+	if i != n {
+		p.swap(i, n)
+	}
+	p.data[n] = indexedItem[K, T]{}
+	p.data = p.data[:n]
+	delete(p.index, item.id)
+	if i != n && !p.down(i) {
+		p.up(i)
+	}
+	if p.IsBoundless() {
+		p.data = slice.Shrink(p.data)
+	}
+	return item
+}
+
+func (p *IndexedPriorityQueue[K, T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if p.comparator(p.data[i].val, p.data[parent].val) >= 0 {
+			break
+		}
+		p.swap(i, parent)
+		i = parent
+	}
+}
+
+// down 返回元素是否发生了移动
+func (p *IndexedPriorityQueue[K, T]) down(i int) bool {
+	start, n := i, len(p.data)
+	for {
+		minPos := i
+		if left := 2*i + 1; left < n && p.comparator(p.data[left].val, p.data[minPos].val) < 0 {
+			minPos = left
+		}
+		if right := 2*i + 2; right < n && p.comparator(p.data[right].val, p.data[minPos].val) < 0 {
+			minPos = right
+		}
+		if minPos == i {
+			break
+		}
+		p.swap(i, minPos)
+		i = minPos
+	}
+	return i > start
+}
+
+func (p *IndexedPriorityQueue[K, T]) swap(i, j int) {
+	p.data[i], p.data[j] = p.data[j], p.data[i]
+	p.index[p.data[i].id] = i
+	p.index[p.data[j].id] = j
+}
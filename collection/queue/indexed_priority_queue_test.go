@@ -0,0 +1,351 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexedPriorityQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		ids      []string
+		id       string
+		val      int
+
+		wantErr error
+		wantLen int
+	}{
+		{
+			name:    "boundless",
+			ids:     []string{"a", "b"},
+			id:      "c",
+			val:     1,
+			wantLen: 3,
+		},
+		{
+			name:     "bounded",
+			capacity: 3,
+			ids:      []string{"a", "b"},
+			id:       "c",
+			val:      1,
+			wantLen:  3,
+		},
+		{
+			name:     "full",
+			capacity: 2,
+			ids:      []string{"a", "b"},
+			id:       "c",
+			val:      1,
+			wantErr:  ErrOutOfCapacity,
+			wantLen:  2,
+		},
+		{
+			name:    "id exists",
+			ids:     []string{"a", "b"},
+			id:      "a",
+			val:     1,
+			wantErr: ErrIDExists,
+			wantLen: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := indexedPriorityQueueOf(tc.capacity, tc.ids...)
+			err := q.Enqueue(tc.id, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+			assert.Equal(t, tc.capacity, q.Cap())
+			checkIndexedPriorityQueue(t, q)
+		})
+	}
+}
+
+func TestIndexedPriorityQueue_Dequeue(t *testing.T) {
+	q := NewIndexedPriorityQueue[string, int](0, comparator.PrimeComparator[int])
+	_, _, err := q.Dequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, _, err = q.Peek()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	vals := map[string]int{"a": 5, "b": 3, "c": 8, "d": 1, "e": 4}
+	for id, val := range vals {
+		require.NoError(t, q.Enqueue(id, val))
+	}
+	id, val, err := q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "d", id)
+	assert.Equal(t, 1, val)
+
+	var ids []string
+	for q.Len() > 0 {
+		id, val, err := q.Dequeue()
+		require.NoError(t, err)
+		assert.Equal(t, vals[id], val)
+		assert.False(t, q.Contains(id))
+		ids = append(ids, id)
+		checkIndexedPriorityQueue(t, q)
+	}
+	assert.Equal(t, []string{"d", "b", "e", "a", "c"}, ids)
+}
+
+func TestIndexedPriorityQueue_Update(t *testing.T) {
+	testCases := []struct {
+		name string
+		id   string
+		val  int
+
+		wantErr error
+		wantIDs []string
+	}{
+		{
+			// a:1 b:2 c:3 d:4 e:5
+			name:    "decrease to head",
+			id:      "e",
+			val:     0,
+			wantIDs: []string{"e", "a", "b", "c", "d"},
+		},
+		{
+			name:    "increase head",
+			id:      "a",
+			val:     10,
+			wantIDs: []string{"b", "c", "d", "e", "a"},
+		},
+		{
+			name:    "increase middle",
+			id:      "b",
+			val:     6,
+			wantIDs: []string{"a", "c", "d", "e", "b"},
+		},
+		{
+			name:    "unchanged",
+			id:      "c",
+			val:     3,
+			wantIDs: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:    "not found",
+			id:      "z",
+			val:     0,
+			wantErr: ErrIDNotFound,
+			wantIDs: []string{"a", "b", "c", "d", "e"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := indexedPriorityQueueOf(0, "a", "b", "c", "d", "e")
+			err := q.Update(tc.id, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			if err == nil {
+				val, ok := q.Get(tc.id)
+				assert.True(t, ok)
+				assert.Equal(t, tc.val, val)
+			}
+			checkIndexedPriorityQueue(t, q)
+			assert.Equal(t, tc.wantIDs, drainIndexedPriorityQueue(t, q))
+		})
+	}
+}
+
+func TestIndexedPriorityQueue_Remove(t *testing.T) {
+	testCases := []struct {
+		name string
+		id   string
+
+		wantVal int
+		wantErr error
+		wantIDs []string
+	}{
+		{
+			name:    "head",
+			id:      "a",
+			wantVal: 1,
+			wantIDs: []string{"b", "c", "d", "e", "f"},
+		},
+		{
+			name:    "middle",
+			id:      "c",
+			wantVal: 3,
+			wantIDs: []string{"a", "b", "d", "e", "f"},
+		},
+		{
+			name:    "last",
+			id:      "f",
+			wantVal: 6,
+			wantIDs: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:    "not found",
+			id:      "z",
+			wantErr: ErrIDNotFound,
+			wantIDs: []string{"a", "b", "c", "d", "e", "f"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := indexedPriorityQueueOf(0, "a", "b", "c", "d", "e", "f")
+			val, err := q.Remove(tc.id)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVal, val)
+			assert.False(t, q.Contains(tc.id))
+			_, ok := q.Get(tc.id)
+			assert.False(t, ok)
+			checkIndexedPriorityQueue(t, q)
+			assert.Equal(t, tc.wantIDs, drainIndexedPriorityQueue(t, q))
+		})
+	}
+}
+
+// TestIndexedPriorityQueue_Random 随机地执行各种操作，和排序的结果对比
+func TestIndexedPriorityQueue_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	q := NewIndexedPriorityQueue[int, int](0, comparator.PrimeComparator[int])
+	expected := make(map[int]int)
+	for i := 0; i < 5000; i++ {
+		id := r.Intn(200)
+		val := r.Intn(1000)
+		switch r.Intn(4) {
+		case 0:
+			err := q.Enqueue(id, val)
+			if _, ok := expected[id]; ok {
+				assert.Equal(t, ErrIDExists, err)
+				continue
+			}
+			require.NoError(t, err)
+			expected[id] = val
+		case 1:
+			err := q.Update(id, val)
+			if _, ok := expected[id]; !ok {
+				assert.Equal(t, ErrIDNotFound, err)
+				continue
+			}
+			require.NoError(t, err)
+			expected[id] = val
+		case 2:
+			got, err := q.Remove(id)
+			want, ok := expected[id]
+			if !ok {
+				assert.Equal(t, ErrIDNotFound, err)
+				continue
+			}
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+			delete(expected, id)
+		case 3:
+			id, val, err := q.Dequeue()
+			if len(expected) == 0 {
+				assert.Equal(t, ErrEmptyQueue, err)
+				continue
+			}
+			require.NoError(t, err)
+			assert.Equal(t, expected[id], val)
+			for _, v := range expected {
+				require.LessOrEqual(t, val, v)
+			}
+			delete(expected, id)
+		}
+		require.Equal(t, len(expected), q.Len())
+	}
+	checkIndexedPriorityQueue(t, q)
+	want := make([]int, 0, len(expected))
+	for _, v := range expected {
+		want = append(want, v)
+	}
+	sort.Ints(want)
+	got := make([]int, 0, len(expected))
+	for q.Len() > 0 {
+		_, val, err := q.Dequeue()
+		require.NoError(t, err)
+		got = append(got, val)
+	}
+	assert.Equal(t, want, got)
+}
+
+func ExampleIndexedPriorityQueue() {
+	// 用 Dijkstra 算法计算从 a 出发到各个顶点的最短距离
+	graph := map[string]map[string]int{
+		"a": {"b": 4, "c": 1},
+		"b": {"d": 1},
+		"c": {"b": 2, "d": 5},
+		"d": {},
+	}
+	dist := map[string]int{"a": 0}
+	q := NewIndexedPriorityQueue[string, int](0, comparator.PrimeComparator[int])
+	_ = q.Enqueue("a", 0)
+	var order []string
+	for q.Len() > 0 {
+		u, d, _ := q.Dequeue()
+		order = append(order, u)
+		for v, w := range graph[u] {
+			old, ok := dist[v]
+			if ok && old <= d+w {
+				continue
+			}
+			dist[v] = d + w
+			if q.Contains(v) {
+				// DecreaseKey
+				_ = q.Update(v, d+w)
+			} else {
+				_ = q.Enqueue(v, d+w)
+			}
+		}
+	}
+	for _, v := range order {
+		fmt.Println(v, dist[v])
+	}
+	// Output:
+	// a 0
+	// c 1
+	// b 3
+	// d 4
+}
+
+// indexedPriorityQueueOf 按照顺序放入 ids，第 i 个 id 的值为 i+1
+func indexedPriorityQueueOf(capacity int, ids ...string) *IndexedPriorityQueue[string, int] {
+	q := NewIndexedPriorityQueue[string, int](capacity, comparator.PrimeComparator[int])
+	for i, id := range ids {
+		_ = q.Enqueue(id, i+1)
+	}
+	return q
+}
+
+func drainIndexedPriorityQueue(t *testing.T, q *IndexedPriorityQueue[string, int]) []string {
+	var res []string
+	for q.Len() > 0 {
+		id, _, err := q.Dequeue()
+		require.NoError(t, err)
+		res = append(res, id)
+	}
+	return res
+}
+
+// checkIndexedPriorityQueue 校验堆的性质和索引是否一致
+func checkIndexedPriorityQueue[K comparable, T any](t *testing.T, q *IndexedPriorityQueue[K, T]) {
+	require.Equal(t, len(q.data), len(q.index))
+	for i, item := range q.data {
+		require.Equal(t, i, q.index[item.id])
+		if i > 0 {
+			require.GreaterOrEqual(t, q.comparator(item.val, q.data[(i-1)/2].val), 0)
+		}
+	}
+}
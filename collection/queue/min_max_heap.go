@@ -0,0 +1,240 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"math/bits"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/igevin/algokit/internal/slice"
+)
+
+// MinMaxHeap 最小最大堆，可以在 O(1) 内查看、在 O(log n) 内取出最小和最大的元素
+// 偶数层（根节点在第 0 层）的节点小于等于它所有的子孙，奇数层的节点大于等于它所有的子孙，
+// 所以最小值是根节点，最大值是根节点的两个孩子之一
+// 当capacity <= 0时，为无界队列，切片容量会动态扩缩容
+// 当capacity > 0 时，为有界队列，初始化后就固定容量，不会扩缩容
+type MinMaxHeap[T any] struct {
+	comparator comparator.Compare[T]
+	capacity   int
+	// data 堆中的元素，根节点在 0 位置
+	data []T
+}
+
+// NewMinMaxHeap 创建最小最大堆 capacity <= 0 时，为无界队列，否则有有界队列
+func NewMinMaxHeap[T any](capacity int, compare comparator.Compare[T]) *MinMaxHeap[T] {
+	sliceCap := capacity
+	if capacity < 1 {
+		capacity = 0
+		sliceCap = 64
+	}
+	return &MinMaxHeap[T]{
+		comparator: compare,
+		capacity:   capacity,
+		data:       make([]T, 0, sliceCap),
+	}
+}
+
+func (h *MinMaxHeap[T]) Len() int {
+	return len(h.data)
+}
+
+// Cap 无界队列返回0，有界队列返回创建队列时设置的值
+func (h *MinMaxHeap[T]) Cap() int {
+	return h.capacity
+}
+
+func (h *MinMaxHeap[T]) IsBoundless() bool {
+	return h.capacity <= 0
+}
+
+func (h *MinMaxHeap[T]) isFull() bool {
+	return h.capacity > 0 && len(h.data) >= h.capacity
+}
+
+// Enqueue 放入元素，有界队列满了的时候返回 ErrOutOfCapacity
+func (h *MinMaxHeap[T]) Enqueue(t T) error {
+	if h.isFull() {
+		return ErrOutOfCapacity
+	}
+	h.data = append(h.data, t)
+	h.up(len(h.data) - 1)
+	return nil
+}
+
+// EnqueueEvict 放入元素，有界队列满了的时候淘汰最大的元素，从而保留最小的 capacity 个元素
+// 如果 t 不小于当前最大的元素，那么被淘汰的就是 t 本身
+// 返回被淘汰的元素，没有元素被淘汰的时候第二个返回值为 false
+// 需要保留最大的 capacity 个元素的时候，把比较函数反过来即可
+func (h *MinMaxHeap[T]) EnqueueEvict(t T) (T, bool) {
+	if !h.isFull() {
+		h.data = append(h.data, t)
+		h.up(len(h.data) - 1)
+		var zero T
+		return zero, false
+	}
+	i := h.maxIndex()
+	evicted := h.data[i]
+	if h.comparator(t, evicted) >= 0 {
+		return t, true
+	}
+	h.data[i] = t
+	// 最大值换成了一个更小的值，它可能比父节点（最小层）还小
+	if i > 0 && h.comparator(h.data[i], h.data[0]) < 0 {
+		h.data[i], h.data[0] = h.data[0], h.data[i]
+	}
+	h.down(i)
+	return evicted, true
+}
+
+// PeekMin 返回最小的元素
+func (h *MinMaxHeap[T]) PeekMin() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.data[0], nil
+}
+
+// PeekMax 返回最大的元素
+func (h *MinMaxHeap[T]) PeekMax() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.data[h.maxIndex()], nil
+}
+
+// PopMin 取出最小的元素
+func (h *MinMaxHeap[T]) PopMin() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.removeAt(0), nil
+}
+
+// PopMax 取出最大的元素
+func (h *MinMaxHeap[T]) PopMax() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.removeAt(h.maxIndex()), nil
+}
+
+// maxIndex 最大值的下标，调用之前必须保证堆不为空
+func (h *MinMaxHeap[T]) maxIndex() int {
+	switch len(h.data) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.comparator(h.data[2], h.data[1]) > 0 {
+		return 2
+	}
+	return 1
+}
+
+// removeAt 用最后一个元素填补下标 i 的空位之后再下沉，i 只会是根节点或者它的孩子
+func (h *MinMaxHeap[T]) removeAt(i int) T {
+	n := len(h.data) - 1
+	res := h.data[i]
+	h.data[i] = h.data[n]
+	var zero T
+	h.data[n] = zero
+	h.data = h.data[:n]
+	if h.IsBoundless() {
+		h.data = slice.Shrink(h.data)
+	}
+	if i < n {
+		h.down(i)
+	}
+	return res
+}
+
+// isMinLevel 下标 i 是否在最小层
+func isMinLevel(i int) bool {
+	return (bits.Len(uint(i+1))-1)%2 == 0
+}
+
+// less 在最小层上就是小于，在最大层上就是大于
+func (h *MinMaxHeap[T]) less(i, j int, minLevel bool) bool {
+	res := h.comparator(h.data[i], h.data[j])
+	if minLevel {
+		return res < 0
+	}
+	return res > 0
+}
+
+func (h *MinMaxHeap[T]) up(i int) {
+	if i == 0 {
+		return
+	}
+	minLevel := isMinLevel(i)
+	parent := (i - 1) / 2
+	// 和父节点不满足关系，说明应该去另外一种层
+	if h.less(parent, i, minLevel) {
+		h.data[i], h.data[parent] = h.data[parent], h.data[i]
+		h.upGrandparent(parent, !minLevel)
+		return
+	}
+	h.upGrandparent(i, minLevel)
+}
+
+// upGrandparent 沿着同一种层（隔一层的祖父节点）上浮
+func (h *MinMaxHeap[T]) upGrandparent(i int, minLevel bool) {
+	for i > 2 {
+		grandparent := ((i-1)/2 - 1) / 2
+		if !h.less(i, grandparent, minLevel) {
+			return
+		}
+		h.data[i], h.data[grandparent] = h.data[grandparent], h.data[i]
+		i = grandparent
+	}
+}
+
+// down 在孩子和孙子中找到最小（最大层则是最大）的节点，和它交换
+func (h *MinMaxHeap[T]) down(i int) {
+	minLevel := isMinLevel(i)
+	n := len(h.data)
+	for {
+		first := 2*i + 1
+		if first >= n {
+			return
+		}
+		m := first
+		// 两个孩子和四个孙子
+		for _, c := range [...]int{first + 1, 2*first + 1, 2*first + 2, 2*first + 3, 2*first + 4} {
+			if c < n && h.less(c, m, minLevel) {
+				m = c
+			}
+		}
+		if !h.less(m, i, minLevel) {
+			return
+		}
+		h.data[i], h.data[m] = h.data[m], h.data[i]
+		if m <= first+1 {
+			// 是孩子，孩子没有子孙在另一种层，不需要继续
+			return
+		}
+		// 是孙子，交换过来的元素可能和孙子的父节点不满足关系
+		if parent := (m - 1) / 2; h.less(parent, m, minLevel) {
+			h.data[m], h.data[parent] = h.data[parent], h.data[m]
+		}
+		i = m
+	}
+}
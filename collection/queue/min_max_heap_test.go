@@ -0,0 +1,361 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinMaxHeap_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		val      int
+
+		wantErr error
+		wantMin int
+		wantMax int
+		wantLen int
+	}{
+		{
+			name:     "empty",
+			capacity: 0,
+			val:      10,
+			wantMin:  10,
+			wantMax:  10,
+			wantLen:  1,
+		},
+		{
+			name:     "new min",
+			capacity: 0,
+			data:     []int{6, 5, 4, 3},
+			val:      1,
+			wantMin:  1,
+			wantMax:  6,
+			wantLen:  5,
+		},
+		{
+			name:     "new max",
+			capacity: 10,
+			data:     []int{6, 5, 4, 3},
+			val:      8,
+			wantMin:  3,
+			wantMax:  8,
+			wantLen:  5,
+		},
+		{
+			name:     "full",
+			capacity: 4,
+			data:     []int{6, 5, 4, 3},
+			val:      1,
+			wantErr:  ErrOutOfCapacity,
+			wantMin:  3,
+			wantMax:  6,
+			wantLen:  4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := minMaxHeapOf(tc.capacity, tc.data...)
+			err := h.Enqueue(tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, h.Len())
+			minVal, err := h.PeekMin()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantMin, minVal)
+			maxVal, err := h.PeekMax()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantMax, maxVal)
+			checkMinMaxHeap(t, h)
+		})
+	}
+}
+
+func TestMinMaxHeap_EnqueueEvict(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		val      int
+
+		wantEvicted int
+		wantOk      bool
+		wantData    []int
+	}{
+		{
+			name:     "not full",
+			capacity: 3,
+			data:     []int{2, 1},
+			val:      3,
+			wantData: []int{1, 2, 3},
+		},
+		{
+			name:     "boundless",
+			capacity: 0,
+			data:     []int{2, 1, 3},
+			val:      4,
+			wantData: []int{1, 2, 3, 4},
+		},
+		{
+			name:        "evict max",
+			capacity:    3,
+			data:        []int{5, 1, 3},
+			val:         2,
+			wantEvicted: 5,
+			wantOk:      true,
+			wantData:    []int{1, 2, 3},
+		},
+		{
+			name:        "evict max by new min",
+			capacity:    5,
+			data:        []int{5, 4, 3, 2, 6},
+			val:         1,
+			wantEvicted: 6,
+			wantOk:      true,
+			wantData:    []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:        "reject",
+			capacity:    3,
+			data:        []int{5, 1, 3},
+			val:         7,
+			wantEvicted: 7,
+			wantOk:      true,
+			wantData:    []int{1, 3, 5},
+		},
+		{
+			name:        "reject equal",
+			capacity:    3,
+			data:        []int{5, 1, 3},
+			val:         5,
+			wantEvicted: 5,
+			wantOk:      true,
+			wantData:    []int{1, 3, 5},
+		},
+		{
+			name:        "capacity one",
+			capacity:    1,
+			data:        []int{5},
+			val:         2,
+			wantEvicted: 5,
+			wantOk:      true,
+			wantData:    []int{2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := minMaxHeapOf(tc.capacity, tc.data...)
+			evicted, ok := h.EnqueueEvict(tc.val)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantEvicted, evicted)
+			checkMinMaxHeap(t, h)
+			assert.Equal(t, tc.wantData, drainMinMaxHeap(t, h))
+		})
+	}
+}
+
+func TestMinMaxHeap_Pop(t *testing.T) {
+	data := []int{9, 2, 7, 4, 5, 6, 3, 8, 1, 10, 0}
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		// pops 为 true 时取出最小值，否则取出最大值
+		pops []bool
+
+		want    []int
+		wantLen int
+	}{
+		{
+			name: "empty",
+			pops: []bool{true, false},
+		},
+		{
+			name: "single",
+			data: []int{1},
+			pops: []bool{false},
+			want: []int{1},
+		},
+		{
+			name:     "min only",
+			capacity: len(data),
+			data:     data,
+			pops:     []bool{true, true, true},
+			want:     []int{0, 1, 2},
+			wantLen:  len(data) - 3,
+		},
+		{
+			name:    "max only",
+			data:    data,
+			pops:    []bool{false, false, false},
+			want:    []int{10, 9, 8},
+			wantLen: len(data) - 3,
+		},
+		{
+			name:    "alternate",
+			data:    data,
+			pops:    []bool{true, false, true, false, true, false},
+			want:    []int{0, 10, 1, 9, 2, 8},
+			wantLen: len(data) - 6,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := minMaxHeapOf(tc.capacity, tc.data...)
+			var got []int
+			for _, popMin := range tc.pops {
+				pop := h.PopMax
+				if popMin {
+					pop = h.PopMin
+				}
+				val, err := pop()
+				if h.Len() == 0 && err != nil {
+					assert.Equal(t, ErrEmptyQueue, err)
+					continue
+				}
+				require.NoError(t, err)
+				got = append(got, val)
+				checkMinMaxHeap(t, h)
+			}
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantLen, h.Len())
+		})
+	}
+}
+
+func TestMinMaxHeap_Peek(t *testing.T) {
+	h := NewMinMaxHeap[int](0, comparator.PrimeComparator[int])
+	_, err := h.PeekMin()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = h.PeekMax()
+	assert.Equal(t, ErrEmptyQueue, err)
+	assert.True(t, h.IsBoundless())
+	assert.Equal(t, 0, h.Cap())
+
+	h = NewMinMaxHeap[int](3, comparator.PrimeComparator[int])
+	assert.False(t, h.IsBoundless())
+	assert.Equal(t, 3, h.Cap())
+}
+
+func TestMinMaxHeap_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, capacity := range []int{0, 1, 2, 7, 64} {
+		h := NewMinMaxHeap[int](capacity, comparator.PrimeComparator[int])
+		var expected []int
+		for i := 0; i < 5000; i++ {
+			val := r.Intn(1000)
+			switch r.Intn(4) {
+			case 0:
+				err := h.Enqueue(val)
+				if capacity > 0 && len(expected) >= capacity {
+					assert.Equal(t, ErrOutOfCapacity, err)
+					continue
+				}
+				require.NoError(t, err)
+				expected = append(expected, val)
+				slices.Sort(expected)
+			case 1:
+				h.EnqueueEvict(val)
+				expected = append(expected, val)
+				slices.Sort(expected)
+				if capacity > 0 && len(expected) > capacity {
+					expected = expected[:capacity]
+				}
+			case 2:
+				val, err := h.PopMin()
+				if len(expected) == 0 {
+					assert.Equal(t, ErrEmptyQueue, err)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, expected[0], val)
+				expected = expected[1:]
+			case 3:
+				val, err := h.PopMax()
+				if len(expected) == 0 {
+					assert.Equal(t, ErrEmptyQueue, err)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, expected[len(expected)-1], val)
+				expected = expected[:len(expected)-1]
+			}
+			require.Equal(t, len(expected), h.Len())
+		}
+		checkMinMaxHeap(t, h)
+		assert.Equal(t, append([]int(nil), expected...), drainMinMaxHeap(t, h))
+	}
+}
+
+func ExampleMinMaxHeap() {
+	// 保留最小的三个元素
+	h := NewMinMaxHeap[int](3, comparator.PrimeComparator[int])
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		if evicted, ok := h.EnqueueEvict(v); ok {
+			fmt.Println("evicted", evicted)
+		}
+	}
+	minVal, _ := h.PopMin()
+	maxVal, _ := h.PopMax()
+	fmt.Println(minVal, maxVal)
+	// Output:
+	// evicted 5
+	// evicted 4
+	// 1 3
+}
+
+func minMaxHeapOf(capacity int, data ...int) *MinMaxHeap[int] {
+	h := NewMinMaxHeap[int](capacity, comparator.PrimeComparator[int])
+	for _, v := range data {
+		_ = h.Enqueue(v)
+	}
+	return h
+}
+
+// drainMinMaxHeap 从小到大取出所有元素
+func drainMinMaxHeap(t *testing.T, h *MinMaxHeap[int]) []int {
+	var res []int
+	for h.Len() > 0 {
+		val, err := h.PopMin()
+		require.NoError(t, err)
+		res = append(res, val)
+	}
+	return res
+}
+
+// checkMinMaxHeap 校验最小层的节点不大于子孙，最大层的节点不小于子孙
+func checkMinMaxHeap[T any](t *testing.T, h *MinMaxHeap[T]) {
+	for i := 1; i < len(h.data); i++ {
+		for p := (i - 1) / 2; ; p = (p - 1) / 2 {
+			res := h.comparator(h.data[p], h.data[i])
+			if isMinLevel(p) {
+				require.LessOrEqual(t, res, 0)
+			} else {
+				require.GreaterOrEqual(t, res, 0)
+			}
+			if p == 0 {
+				break
+			}
+		}
+	}
+}
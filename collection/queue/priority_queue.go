@@ -16,6 +16,8 @@ package queue
 
 import (
 	"errors"
+	"iter"
+	"slices"
 
 	"github.com/igevin/algokit/comparator"
 	"github.com/igevin/algokit/internal/slice"
@@ -73,14 +75,52 @@ func (p *PriorityQueue[T]) Enqueue(t T) error {
 	}
 
 	p.data = append(p.data, t)
-	node, parent := len(p.data)-1, (len(p.data)-1)/2
-	for parent > 0 && p.comparator(p.data[node], p.data[parent]) < 0 {
+	p.siftUp(len(p.data) - 1)
+	return nil
+}
+
+// EnqueueAll 放入多个元素，有界队列放不下所有元素的时候返回 ErrOutOfCapacity，并且一个都不放入
+func (p *PriorityQueue[T]) EnqueueAll(ts ...T) error {
+	if p.capacity > 0 && p.Len()+len(ts) > p.capacity {
+		return ErrOutOfCapacity
+	}
+	p.addAll(ts)
+	return nil
+}
+
+// Merge 把 other 中的所有元素放入当前队列，other 不会被修改
+// 有界队列放不下所有元素的时候返回 ErrOutOfCapacity，并且一个都不放入
+func (p *PriorityQueue[T]) Merge(other *PriorityQueue[T]) error {
+	return p.EnqueueAll(other.data[1:]...)
+}
+
+// addAll 新增的元素不少于已有的元素时，整体重新建堆只需要 O(n)，
+// 否则逐个上浮，只需要 O(m log n)
+func (p *PriorityQueue[T]) addAll(ts []T) {
+	n := p.Len()
+	p.data = append(p.data, ts...)
+	if len(ts) < n {
+		for i := n + 1; i < len(p.data); i++ {
+			p.siftUp(i)
+		}
+		return
+	}
+	p.buildHeap()
+}
+
+func (p *PriorityQueue[T]) siftUp(node int) {
+	for parent := node / 2; parent > 0 && p.comparator(p.data[node], p.data[parent]) < 0; parent = node / 2 {
 		p.data[parent], p.data[node] = p.data[node], p.data[parent]
 		node = parent
-		parent = parent / 2
 	}
+}
 
-	return nil
+// buildHeap 从最后一个非叶子节点开始逐个下沉，时间复杂度 O(n)
+func (p *PriorityQueue[T]) buildHeap() {
+	n := p.Len()
+	for i := n / 2; i > 0; i-- {
+		p.heapify(p.data, n, i)
+	}
 }
 
 func (p *PriorityQueue[T]) Dequeue() (T, error) {
@@ -97,6 +137,50 @@ func (p *PriorityQueue[T]) Dequeue() (T, error) {
 	return pop, nil
 }
 
+// DequeueN 按照优先级依次取出最多 k 个元素，元素不足 k 个的时候全部取出
+func (p *PriorityQueue[T]) DequeueN(k int) []T {
+	k = min(k, p.Len())
+	if k <= 0 {
+		return []T{}
+	}
+	res := make([]T, 0, k)
+	for i := 0; i < k; i++ {
+		t, _ := p.Dequeue()
+		res = append(res, t)
+	}
+	return res
+}
+
+// Clone 复制一个容量和元素都相同的队列，两者之后互不影响
+func (p *PriorityQueue[T]) Clone() *PriorityQueue[T] {
+	data := make([]T, len(p.data), cap(p.data))
+	copy(data, p.data)
+	return &PriorityQueue[T]{
+		comparator: p.comparator,
+		capacity:   p.capacity,
+		data:       data,
+	}
+}
+
+// ToSortedSlice 按照出队的顺序返回所有元素，不会修改队列
+func (p *PriorityQueue[T]) ToSortedSlice() []T {
+	res := slices.Clone(p.data[1:])
+	slices.SortFunc(res, p.comparator)
+	return res
+}
+
+// All 遍历队列中的所有元素，顺序是堆中的存储顺序，不是出队的顺序
+// 迭代过程中不能修改队列
+func (p *PriorityQueue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, t := range p.data[1:] {
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
+
 func (p *PriorityQueue[T]) shrinkIfNecessary() {
 	if p.IsBoundless() {
 		p.data = slice.Shrink[T](p.data)
@@ -133,3 +217,25 @@ func NewPriorityQueue[T any](capacity int, compare comparator.Compare[T]) *Prior
 		comparator: compare,
 	}
 }
+
+// NewPriorityQueueOf 用 ts 中的元素创建优先队列，ts 不会被修改
+// 直接自底向上建堆，时间复杂度 O(n)，比逐个 Enqueue 的 O(n log n) 更快
+// capacity <= 0 时，为无界队列；有界队列放不下 ts 中所有元素的时候返回 ErrOutOfCapacity
+func NewPriorityQueueOf[T any](ts []T, capacity int, compare comparator.Compare[T]) (*PriorityQueue[T], error) {
+	if capacity > 0 && len(ts) > capacity {
+		return nil, ErrOutOfCapacity
+	}
+	sliceCap := capacity + 1
+	if capacity < 1 {
+		capacity = 0
+		sliceCap = max(64, len(ts)+1)
+	}
+	data := make([]T, 1, sliceCap)
+	p := &PriorityQueue[T]{
+		capacity:   capacity,
+		data:       append(data, ts...),
+		comparator: compare,
+	}
+	p.buildHeap()
+	return p, nil
+}
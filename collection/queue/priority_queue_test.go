@@ -15,6 +15,7 @@
 package queue
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/igevin/algokit/comparator"
@@ -461,6 +462,255 @@ func TestPriorityQueue_Shrink(t *testing.T) {
 	}
 }
 
+func TestNewPriorityQueueOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []int
+		capacity int
+
+		wantErr error
+		wantCap int
+		want    []int
+	}{
+		{
+			name: "nil",
+			want: []int{},
+		},
+		{
+			name:     "boundless",
+			data:     []int{6, 1, 5, 2, 4, 3, 3},
+			capacity: 0,
+			want:     []int{1, 2, 3, 3, 4, 5, 6},
+		},
+		{
+			name:     "bounded",
+			data:     []int{6, 1, 5, 2, 4, 3},
+			capacity: 8,
+			wantCap:  8,
+			want:     []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "exactly full",
+			data:     []int{3, 2, 1},
+			capacity: 3,
+			wantCap:  3,
+			want:     []int{1, 2, 3},
+		},
+		{
+			name:     "out of capacity",
+			data:     []int{3, 2, 1},
+			capacity: 2,
+			wantErr:  ErrOutOfCapacity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := slices.Clone(tc.data)
+			q, err := NewPriorityQueueOf(data, tc.capacity, comparator.PrimeComparator[int])
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			// 不会修改传入的切片
+			assert.Equal(t, tc.data, data)
+			assert.Equal(t, tc.wantCap, q.Cap())
+			checkPriorityQueue(t, q)
+			assert.Equal(t, tc.want, q.DequeueN(q.Len()+1))
+		})
+	}
+}
+
+func TestPriorityQueue_EnqueueAll(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		vals     []int
+
+		wantErr error
+		want    []int
+	}{
+		{
+			name: "empty",
+			vals: []int{3, 1, 2},
+			want: []int{1, 2, 3},
+		},
+		{
+			name: "nothing",
+			data: []int{2, 1},
+			want: []int{1, 2},
+		},
+		{
+			name: "fewer than existing",
+			data: []int{8, 3, 6, 1, 9},
+			vals: []int{4, 0},
+			want: []int{0, 1, 3, 4, 6, 8, 9},
+		},
+		{
+			name: "more than existing",
+			data: []int{8, 3},
+			vals: []int{4, 0, 7, 1},
+			want: []int{0, 1, 3, 4, 7, 8},
+		},
+		{
+			name:     "bounded",
+			capacity: 4,
+			data:     []int{2},
+			vals:     []int{4, 1, 3},
+			want:     []int{1, 2, 3, 4},
+		},
+		{
+			name:     "out of capacity",
+			capacity: 3,
+			data:     []int{2},
+			vals:     []int{4, 1, 3},
+			wantErr:  ErrOutOfCapacity,
+			want:     []int{2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := priorityQueueOf(tc.capacity, tc.data, comparator.PrimeComparator[int])
+			assert.Equal(t, tc.wantErr, q.EnqueueAll(tc.vals...))
+			checkPriorityQueue(t, q)
+			assert.Equal(t, tc.want, q.ToSortedSlice())
+		})
+	}
+}
+
+func TestPriorityQueue_Merge(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		other    []int
+
+		wantErr error
+		want    []int
+	}{
+		{
+			name:  "boundless",
+			data:  []int{5, 1, 3},
+			other: []int{4, 2, 6},
+			want:  []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "bounded",
+			capacity: 4,
+			data:     []int{5, 1},
+			other:    []int{4, 2},
+			want:     []int{1, 2, 4, 5},
+		},
+		{
+			name:     "out of capacity",
+			capacity: 3,
+			data:     []int{5, 1},
+			other:    []int{4, 2},
+			wantErr:  ErrOutOfCapacity,
+			want:     []int{1, 5},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := priorityQueueOf(tc.capacity, tc.data, comparator.PrimeComparator[int])
+			other := priorityQueueOf(0, tc.other, comparator.PrimeComparator[int])
+			assert.Equal(t, tc.wantErr, q.Merge(other))
+			checkPriorityQueue(t, q)
+			assert.Equal(t, tc.want, q.ToSortedSlice())
+			// other 不会被修改
+			assert.Equal(t, len(tc.other), other.Len())
+		})
+	}
+
+	t.Run("merge self", func(t *testing.T) {
+		q := priorityQueueOf(0, []int{2, 1}, comparator.PrimeComparator[int])
+		require.NoError(t, q.Merge(q))
+		assert.Equal(t, []int{1, 1, 2, 2}, q.ToSortedSlice())
+	})
+}
+
+func TestPriorityQueue_DequeueN(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []int
+		k    int
+
+		want    []int
+		wantLen int
+	}{
+		{
+			name: "empty",
+			k:    3,
+			want: []int{},
+		},
+		{
+			name:    "negative",
+			data:    []int{1, 2},
+			k:       -1,
+			want:    []int{},
+			wantLen: 2,
+		},
+		{
+			name:    "part",
+			data:    []int{5, 2, 4, 1, 3},
+			k:       2,
+			want:    []int{1, 2},
+			wantLen: 3,
+		},
+		{
+			name: "more than len",
+			data: []int{3, 1, 2},
+			k:    5,
+			want: []int{1, 2, 3},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := priorityQueueOf(0, tc.data, comparator.PrimeComparator[int])
+			assert.Equal(t, tc.want, q.DequeueN(tc.k))
+			assert.Equal(t, tc.wantLen, q.Len())
+		})
+	}
+}
+
+func TestPriorityQueue_Clone(t *testing.T) {
+	q := priorityQueueOf(5, []int{3, 1, 2}, comparator.PrimeComparator[int])
+	c := q.Clone()
+	assert.Equal(t, q.Cap(), c.Cap())
+	require.NoError(t, c.Enqueue(0))
+	_, err := q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, q.ToSortedSlice())
+	assert.Equal(t, []int{0, 1, 2, 3}, c.ToSortedSlice())
+}
+
+func TestPriorityQueue_All(t *testing.T) {
+	data := []int{6, 1, 5, 2, 4, 3}
+	q := priorityQueueOf(0, data, comparator.PrimeComparator[int])
+	got := slices.Collect(q.All())
+	assert.ElementsMatch(t, data, got)
+	// 第一个元素一定是堆顶
+	assert.Equal(t, 1, got[0])
+
+	// 提前结束迭代
+	var cnt int
+	for range q.All() {
+		cnt++
+		if cnt == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, cnt)
+	assert.Empty(t, slices.Collect(NewPriorityQueue[int](0, comparator.PrimeComparator[int]).All()))
+}
+
+// checkPriorityQueue 校验每个节点都不小于它的父节点
+func checkPriorityQueue[T any](t *testing.T, q *PriorityQueue[T]) {
+	for i := 2; i < len(q.data); i++ {
+		require.GreaterOrEqual(t, q.comparator(q.data[i], q.data[i/2]), 0)
+	}
+}
+
 func priorityQueueOf(capacity int, data []int, compare comparator.Compare[int]) *PriorityQueue[int] {
 	q := NewPriorityQueue[int](capacity, compare)
 	for _, el := range data {
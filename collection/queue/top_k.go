@@ -0,0 +1,94 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"slices"
+
+	"github.com/igevin/algokit/comparator"
+)
+
+// TopK 从数据流中保留最大的 k 个元素
+// 内部是一个容量为 k 的小顶堆，堆顶就是目前保留的元素中最小的，新元素只有比它大才会替换它，
+// 所以每次 Offer 的时间复杂度是 O(log k)
+// 非并发安全；并行处理的时候，每个协程各自使用一个 TopK，最后用 Merge 合并
+type TopK[T any] struct {
+	k int
+	q *PriorityQueue[T]
+}
+
+// NewTopK 创建 TopK，k <= 0 的时候不会保留任何元素
+func NewTopK[T any](k int, compare comparator.Compare[T]) *TopK[T] {
+	if k < 0 {
+		k = 0
+	}
+	return &TopK[T]{
+		k: k,
+		// 容量至少为 1，避免变成无界队列
+		q: NewPriorityQueue(max(k, 1), compare),
+	}
+}
+
+// Offer 放入元素，返回它是否被保留下来
+// 已经保留了 k 个元素的时候，只有比其中最小的元素大，才会替换掉最小的元素
+func (t *TopK[T]) Offer(val T) bool {
+	if t.k == 0 {
+		return false
+	}
+	q := t.q
+	if !q.isFull() {
+		_ = q.Enqueue(val)
+		return true
+	}
+	if q.comparator(val, q.data[1]) <= 0 {
+		return false
+	}
+	q.data[1] = val
+	q.heapify(q.data, q.Len(), 1)
+	return true
+}
+
+// Merge 把 other 保留的元素合并进来，other 不会被修改
+// 合并之后的结果和把两边的数据流都 Offer 到同一个 TopK 中是一样的
+func (t *TopK[T]) Merge(other *TopK[T]) {
+	vals := other.q.data[1:]
+	if other == t {
+		// 和自己合并的时候，Offer 会修改正在遍历的切片
+		vals = slices.Clone(vals)
+	}
+	for _, val := range vals {
+		t.Offer(val)
+	}
+}
+
+// Result 返回保留下来的元素，按照从大到小排列
+// 不会修改 TopK，之后可以继续 Offer
+func (t *TopK[T]) Result() []T {
+	res := slices.Clone(t.q.data[1:])
+	slices.SortFunc(res, func(a, b T) int {
+		return t.q.comparator(b, a)
+	})
+	return res
+}
+
+// Len 当前保留的元素个数，不会超过 k
+func (t *TopK[T]) Len() int {
+	return t.q.Len()
+}
+
+// K 返回创建时指定的 k
+func (t *TopK[T]) K() int {
+	return t.k
+}
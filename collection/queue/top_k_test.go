@@ -0,0 +1,183 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopK_Offer(t *testing.T) {
+	testCases := []struct {
+		name string
+		k    int
+		data []int
+		val  int
+
+		wantOk     bool
+		wantResult []int
+	}{
+		{
+			name:       "zero k",
+			k:          0,
+			val:        1,
+			wantResult: []int{},
+		},
+		{
+			name:       "negative k",
+			k:          -1,
+			val:        1,
+			wantResult: []int{},
+		},
+		{
+			name:       "not full",
+			k:          3,
+			data:       []int{1, 5},
+			val:        3,
+			wantOk:     true,
+			wantResult: []int{5, 3, 1},
+		},
+		{
+			name:       "replace min",
+			k:          3,
+			data:       []int{1, 5, 3},
+			val:        4,
+			wantOk:     true,
+			wantResult: []int{5, 4, 3},
+		},
+		{
+			name:       "smaller than min",
+			k:          3,
+			data:       []int{2, 5, 3},
+			val:        1,
+			wantResult: []int{5, 3, 2},
+		},
+		{
+			name:       "equal to min",
+			k:          3,
+			data:       []int{2, 5, 3},
+			val:        2,
+			wantResult: []int{5, 3, 2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topK := topKOf(tc.k, tc.data...)
+			assert.Equal(t, tc.wantOk, topK.Offer(tc.val))
+			assert.Equal(t, tc.wantResult, topK.Result())
+			assert.Equal(t, len(tc.wantResult), topK.Len())
+			assert.Equal(t, max(tc.k, 0), topK.K())
+		})
+	}
+}
+
+func TestTopK_Merge(t *testing.T) {
+	testCases := []struct {
+		name  string
+		k     int
+		left  []int
+		right []int
+
+		wantResult []int
+	}{
+		{
+			name:       "both empty",
+			k:          3,
+			wantResult: []int{},
+		},
+		{
+			name:       "right empty",
+			k:          3,
+			left:       []int{1, 2},
+			wantResult: []int{2, 1},
+		},
+		{
+			name:       "left empty",
+			k:          3,
+			right:      []int{4, 1, 3, 2},
+			wantResult: []int{4, 3, 2},
+		},
+		{
+			name:       "interleaved",
+			k:          4,
+			left:       []int{1, 8, 3, 6, 5},
+			right:      []int{7, 2, 9, 4},
+			wantResult: []int{9, 8, 7, 6},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			left, right := topKOf(tc.k, tc.left...), topKOf(tc.k, tc.right...)
+			rightResult := right.Result()
+			left.Merge(right)
+			assert.Equal(t, tc.wantResult, left.Result())
+			// right 不会被修改
+			assert.Equal(t, rightResult, right.Result())
+		})
+	}
+
+	t.Run("merge self", func(t *testing.T) {
+		topK := topKOf(3, 1, 2, 3)
+		topK.Merge(topK)
+		assert.Equal(t, []int{3, 3, 2}, topK.Result())
+	})
+}
+
+// TestTopK_Random 把数据流切分成多段，分别统计之后合并，结果和排序之后取前 k 个一样
+func TestTopK_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	data := make([]int, 10000)
+	for i := range data {
+		data[i] = r.Intn(5000)
+	}
+	sorted := slices.Clone(data)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+	for _, k := range []int{1, 10, 100} {
+		const parts = 4
+		res := NewTopK[int](k, comparator.PrimeComparator[int])
+		step := len(data) / parts
+		for i := 0; i < len(data); i += step {
+			part := topKOf(k, data[i:i+step]...)
+			require.Equal(t, k, part.Len())
+			res.Merge(part)
+		}
+		assert.Equal(t, sorted[:k], res.Result())
+	}
+}
+
+func ExampleTopK() {
+	topK := NewTopK[int](3, comparator.PrimeComparator[int])
+	for _, v := range []int{5, 1, 9, 3, 7} {
+		topK.Offer(v)
+	}
+	fmt.Println(topK.Result())
+	// Output:
+	// [9 7 5]
+}
+
+func topKOf(k int, data ...int) *TopK[int] {
+	res := NewTopK[int](k, comparator.PrimeComparator[int])
+	for _, v := range data {
+		res.Offer(v)
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slice
+
+import (
+	"math/bits"
+	"slices"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/igevin/algokit/internal/slice"
+)
+
+// insertionSortThreshold 区间小于这个长度的时候直接插入排序
+const insertionSortThreshold = 12
+
+// NthElement 原地重排 src，使得 src[n] 就是 src 排序之后位于 n 的元素，
+// 并且它前面的元素都不大于它，后面的元素都不小于它，返回这个元素
+// 使用 introselect：三数取中的快速选择，递归深度超过 2*log(len) 之后退化为排序，
+// 平均时间复杂度 O(n)，最坏 O(n log n)
+func NthElement[T any](src []T, n int, compare comparator.Compare[T]) (T, error) {
+	if n < 0 || n >= len(src) {
+		var t T
+		return t, ErrSliceWrapped(slice.ErrOutOfRange)
+	}
+	nthElement(src, n, compare)
+	return src[n], nil
+}
+
+func nthElement[T any](src []T, n int, compare comparator.Compare[T]) {
+	lo, hi := 0, len(src)
+	limit := 2 * bits.Len(uint(len(src)))
+	for hi-lo > insertionSortThreshold {
+		if limit == 0 {
+			slices.SortFunc(src[lo:hi], compare)
+			return
+		}
+		limit--
+		lt, gt := partition(src, lo, hi, compare)
+		switch {
+		case n < lt:
+			hi = lt
+		case n >= gt:
+			lo = gt
+		default:
+			// n 落在和基准相等的区间里
+			return
+		}
+	}
+	insertionSort(src[lo:hi], compare)
+}
+
+// partition 以三数取中选出的基准把 [lo, hi) 三路划分，
+// 返回 lt 和 gt，使得 [lo, lt) 小于基准，[lt, gt) 等于基准，[gt, hi) 大于基准
+// 三路划分可以避免大量重复元素的时候退化
+func partition[T any](src []T, lo, hi int, compare comparator.Compare[T]) (int, int) {
+	mid := lo + (hi-lo)/2
+	if compare(src[mid], src[lo]) < 0 {
+		src[mid], src[lo] = src[lo], src[mid]
+	}
+	if compare(src[hi-1], src[mid]) < 0 {
+		src[hi-1], src[mid] = src[mid], src[hi-1]
+		if compare(src[mid], src[lo]) < 0 {
+			src[mid], src[lo] = src[lo], src[mid]
+		}
+	}
+	pivot := src[mid]
+	lt, i, gt := lo, lo, hi
+	for i < gt {
+		switch res := compare(src[i], pivot); {
+		case res < 0:
+			src[lt], src[i] = src[i], src[lt]
+			lt++
+			i++
+		case res > 0:
+			gt--
+			src[i], src[gt] = src[gt], src[i]
+		default:
+			i++
+		}
+	}
+	return lt, gt
+}
+
+func insertionSort[T any](src []T, compare comparator.Compare[T]) {
+	for i := 1; i < len(src); i++ {
+		for j := i; j > 0 && compare(src[j], src[j-1]) < 0; j-- {
+			src[j], src[j-1] = src[j-1], src[j]
+		}
+	}
+}
+
+// TopK 返回 src 中最大的 k 个元素，按照从大到小排列，不会修改 src
+// k 大于 len(src) 的时候返回所有元素；时间复杂度 O(n + k log k)
+func TopK[T any](src []T, k int, compare comparator.Compare[T]) []T {
+	if k <= 0 || len(src) == 0 {
+		return []T{}
+	}
+	k = min(k, len(src))
+	res := slices.Clone(src)
+	// 反过来比较，最大的 k 个元素就会被换到前面
+	desc := func(a, b T) int {
+		return compare(b, a)
+	}
+	if k < len(res) {
+		nthElement(res, k-1, desc)
+		// 只保留前 k 个，避免持有整个拷贝
+		res = slices.Clone(res[:k])
+	}
+	slices.SortFunc(res, desc)
+	return res
+}
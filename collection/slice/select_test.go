@@ -0,0 +1,195 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slice
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/igevin/algokit/internal/slice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNthElement(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  []int
+		n    int
+
+		expectRes int
+		expectErr error
+	}{
+		{
+			name:      "nil",
+			expectErr: slice.ErrOutOfRange,
+		},
+		{
+			name:      "negative",
+			src:       []int{1, 2},
+			n:         -1,
+			expectErr: slice.ErrOutOfRange,
+		},
+		{
+			name:      "out of range",
+			src:       []int{1, 2},
+			n:         2,
+			expectErr: slice.ErrOutOfRange,
+		},
+		{
+			name:      "single",
+			src:       []int{5},
+			expectRes: 5,
+		},
+		{
+			name:      "small",
+			src:       []int{5, 3, 1, 4, 2},
+			n:         1,
+			expectRes: 2,
+		},
+		{
+			name:      "first",
+			src:       []int{9, 15, 3, 7, 11, 20, 1, 8, 14, 6, 2, 13, 5, 19, 4},
+			n:         0,
+			expectRes: 1,
+		},
+		{
+			name:      "last",
+			src:       []int{9, 15, 3, 7, 11, 20, 1, 8, 14, 6, 2, 13, 5, 19, 4},
+			n:         14,
+			expectRes: 20,
+		},
+		{
+			name:      "middle",
+			src:       []int{9, 15, 3, 7, 11, 20, 1, 8, 14, 6, 2, 13, 5, 19, 4},
+			n:         7,
+			expectRes: 8,
+		},
+		{
+			name:      "duplicates",
+			src:       []int{2, 1, 2, 2, 3, 2, 2, 1, 2, 2, 3, 2, 2, 2, 2, 1},
+			n:         12,
+			expectRes: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := NthElement(tc.src, tc.n, comparator.PrimeComparator[int])
+			assert.ErrorIs(t, err, tc.expectErr)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.expectRes, res)
+			checkNthElement(t, tc.src, tc.n)
+		})
+	}
+}
+
+func TestNthElement_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, size := range []int{1, 13, 100, 1000} {
+		for _, maxVal := range []int{3, 1 << 20} {
+			src := make([]int, size)
+			for i := range src {
+				src[i] = r.Intn(maxVal)
+			}
+			sorted := slices.Clone(src)
+			slices.Sort(sorted)
+			for i := 0; i < 20; i++ {
+				n := r.Intn(size)
+				res, err := NthElement(src, n, comparator.PrimeComparator[int])
+				require.NoError(t, err)
+				require.Equal(t, sorted[n], res)
+				checkNthElement(t, src, n)
+			}
+		}
+	}
+}
+
+// TestNthElement_Sorted 已经有序和逆序的输入不会退化
+func TestNthElement_Sorted(t *testing.T) {
+	asc := make([]int, 10000)
+	for i := range asc {
+		asc[i] = i
+	}
+	desc := slices.Clone(asc)
+	slices.Reverse(desc)
+	for _, src := range [][]int{asc, desc} {
+		res, err := NthElement(src, 5000, comparator.PrimeComparator[int])
+		require.NoError(t, err)
+		assert.Equal(t, 5000, res)
+		checkNthElement(t, src, 5000)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  []int
+		k    int
+
+		expectRes []int
+	}{
+		{
+			name:      "nil",
+			k:         3,
+			expectRes: []int{},
+		},
+		{
+			name:      "zero k",
+			src:       []int{1, 2, 3},
+			expectRes: []int{},
+		},
+		{
+			name:      "k greater than len",
+			src:       []int{2, 3, 1},
+			k:         5,
+			expectRes: []int{3, 2, 1},
+		},
+		{
+			name:      "normal",
+			src:       []int{9, 15, 3, 7, 11, 20, 1, 8, 14, 6, 2, 13, 5, 19, 4},
+			k:         4,
+			expectRes: []int{20, 19, 15, 14},
+		},
+		{
+			name:      "duplicates",
+			src:       []int{1, 3, 3, 2, 3, 1},
+			k:         2,
+			expectRes: []int{3, 3},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := slices.Clone(tc.src)
+			res := TopK(src, tc.k, comparator.PrimeComparator[int])
+			assert.Equal(t, tc.expectRes, res)
+			// 不会修改 src
+			assert.Equal(t, tc.src, src)
+		})
+	}
+}
+
+// checkNthElement 校验 src[n] 前面的元素都不大于它，后面的元素都不小于它
+func checkNthElement(t *testing.T, src []int, n int) {
+	for i := 0; i < n; i++ {
+		require.LessOrEqual(t, src[i], src[n])
+	}
+	for i := n + 1; i < len(src); i++ {
+		require.GreaterOrEqual(t, src[i], src[n])
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heap
+
+import (
+	"github.com/igevin/algokit/comparator"
+	"github.com/igevin/algokit/internal/slice"
+)
+
+var _ Heap[any] = &DaryHeap[any]{}
+
+// DaryHeap d 叉堆，根节点在 0 位置，下标为 i 的节点的孩子是 d*i+1 到 d*i+d
+// 叉数越大，树越矮，Push 越快，但是 Pop 的时候每一层要比较的孩子越多；
+// 4 叉堆通常比二叉堆更加缓存友好
+type DaryHeap[T any] struct {
+	comparator comparator.Compare[T]
+	d          int
+	data       []T
+}
+
+// NewDaryHeap 创建 d 叉堆，d < 2 时按照 2 处理
+func NewDaryHeap[T any](d int, compare comparator.Compare[T]) *DaryHeap[T] {
+	return &DaryHeap[T]{
+		comparator: compare,
+		d:          max(d, 2),
+	}
+}
+
+// NewDaryHeapOf 用 ts 建堆，时间复杂度为 O(n)，会直接使用 ts 作为底层存储
+func NewDaryHeapOf[T any](d int, ts []T, compare comparator.Compare[T]) *DaryHeap[T] {
+	h := NewDaryHeap(d, compare)
+	h.data = ts
+	h.heapify()
+	return h
+}
+
+func (h *DaryHeap[T]) Push(t T) {
+	h.data = append(h.data, t)
+	h.up(len(h.data) - 1)
+}
+
+func (h *DaryHeap[T]) Pop() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyHeap
+	}
+	n := len(h.data) - 1
+	top := h.data[0]
+	h.data[0] = h.data[n]
+	var zero T
+	h.data[n] = zero
+	h.data = slice.Shrink(h.data[:n])
+	h.down(0)
+	return top, nil
+}
+
+func (h *DaryHeap[T]) Peek() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyHeap
+	}
+	return h.data[0], nil
+}
+
+func (h *DaryHeap[T]) Len() int {
+	return len(h.data)
+}
+
+// Meld 把两个堆的元素拼接起来重新建堆，时间复杂度为 O(n+m)
+func (h *DaryHeap[T]) Meld(other Heap[T]) {
+	if other == Heap[T](h) {
+		return
+	}
+	o, ok := other.(*DaryHeap[T])
+	if !ok {
+		drain[T](h, other)
+		return
+	}
+	h.data = append(h.data, o.data...)
+	o.data = nil
+	h.heapify()
+}
+
+// heapify 自底向上建堆，从最后一个非叶子节点开始下沉
+func (h *DaryHeap[T]) heapify() {
+	for i := (len(h.data) - 2) / h.d; i >= 0; i-- {
+		h.down(i)
+	}
+}
+
+func (h *DaryHeap[T]) up(i int) {
+	t := h.data[i]
+	for i > 0 {
+		parent := (i - 1) / h.d
+		if h.comparator(t, h.data[parent]) >= 0 {
+			break
+		}
+		h.data[i] = h.data[parent]
+		i = parent
+	}
+	h.data[i] = t
+}
+
+func (h *DaryHeap[T]) down(i int) {
+	n := len(h.data)
+	if i >= n {
+		return
+	}
+	t := h.data[i]
+	for {
+		first := h.d*i + 1
+		if first >= n {
+			break
+		}
+		minPos := first
+		for c := first + 1; c < min(first+h.d, n); c++ {
+			if h.comparator(h.data[c], h.data[minPos]) < 0 {
+				minPos = c
+			}
+		}
+		if h.comparator(h.data[minPos], t) >= 0 {
+			break
+		}
+		h.data[i] = h.data[minPos]
+		i = minPos
+	}
+	h.data[i] = t
+}
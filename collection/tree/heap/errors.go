@@ -0,0 +1,25 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heap
+
+import "errors"
+
+var (
+	ErrEmptyHeap = errors.New("algokit: 堆为空")
+	// ErrInvalidNode 节点为 nil、不是这种堆的节点，或者已经被弹出
+	ErrInvalidNode = errors.New("algokit: 无效的堆节点")
+	// ErrKeyIncreased DecreaseKey 的新值比原来的值大
+	ErrKeyIncreased = errors.New("algokit: 新的值比原来的值大")
+)
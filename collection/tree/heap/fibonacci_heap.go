@@ -0,0 +1,253 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heap
+
+import "github.com/igevin/algokit/comparator"
+
+var _ AddressableHeap[any] = &FibonacciHeap[any]{}
+
+// FibonacciHeap 斐波那契堆
+// Push、Meld、DecreaseKey 均摊 O(1)，Pop 均摊 O(log n)
+// 理论复杂度最好，但是常数较大，只有 DecreaseKey 非常频繁的时候才有优势
+type FibonacciHeap[T any] struct {
+	comparator comparator.Compare[T]
+	// min 最小的根节点，所有的根节点组成一个循环双向链表
+	min  *FibonacciNode[T]
+	size int
+	// roots 和 byDegree 是 consolidate 用到的临时空间，复用以减少内存分配
+	roots    []*FibonacciNode[T]
+	byDegree []*FibonacciNode[T]
+}
+
+// FibonacciNode 斐波那契堆的节点
+type FibonacciNode[T any] struct {
+	val    T
+	parent *FibonacciNode[T]
+	// child 任意一个孩子，兄弟之间通过 left 和 right 组成循环双向链表
+	child       *FibonacciNode[T]
+	left, right *FibonacciNode[T]
+	degree      int
+	// marked 成为非根节点之后是否失去过孩子
+	marked bool
+	// removed 节点已经被弹出
+	removed bool
+}
+
+func (n *FibonacciNode[T]) Value() T {
+	return n.val
+}
+
+func NewFibonacciHeap[T any](compare comparator.Compare[T]) *FibonacciHeap[T] {
+	return &FibonacciHeap[T]{
+		comparator: compare,
+	}
+}
+
+func (h *FibonacciHeap[T]) Push(t T) {
+	h.Insert(t)
+}
+
+// Insert 只是把节点加入根链表，整理的工作推迟到 Pop
+func (h *FibonacciHeap[T]) Insert(t T) Node[T] {
+	n := &FibonacciNode[T]{val: t}
+	n.left, n.right = n, n
+	h.addRoot(n)
+	h.size++
+	return n
+}
+
+func (h *FibonacciHeap[T]) Pop() (T, error) {
+	z := h.min
+	if z == nil {
+		var t T
+		return t, ErrEmptyHeap
+	}
+	// 孩子都提升为根节点
+	for z.child != nil {
+		c := z.child
+		h.removeFromList(c, &z.child)
+		c.parent = nil
+		c.marked = false
+		h.addRoot(c)
+	}
+	next := z.right
+	h.removeFromList(z, &h.min)
+	if next != z {
+		h.min = next
+		h.consolidate()
+	}
+	h.size--
+	z.removed = true
+	z.left, z.right = nil, nil
+	return z.val, nil
+}
+
+func (h *FibonacciHeap[T]) Peek() (T, error) {
+	if h.min == nil {
+		var t T
+		return t, ErrEmptyHeap
+	}
+	return h.min.val, nil
+}
+
+func (h *FibonacciHeap[T]) Len() int {
+	return h.size
+}
+
+// Meld 合并另一个斐波那契堆只需要拼接两个根链表
+func (h *FibonacciHeap[T]) Meld(other Heap[T]) {
+	if other == Heap[T](h) {
+		return
+	}
+	o, ok := other.(*FibonacciHeap[T])
+	if !ok {
+		drain[T](h, other)
+		return
+	}
+	if o.min == nil {
+		return
+	}
+	if h.min == nil {
+		h.min = o.min
+	} else {
+		// 把两个循环链表拼起来
+		hr, or := h.min.right, o.min.right
+		h.min.right, or.left = or, h.min
+		o.min.right, hr.left = hr, o.min
+		if h.comparator(o.min.val, h.min.val) < 0 {
+			h.min = o.min
+		}
+	}
+	h.size += o.size
+	o.min, o.size = nil, 0
+}
+
+// DecreaseKey 值比父节点小的时候把节点剪下来放到根链表，并且级联地剪掉已经被标记的祖先
+func (h *FibonacciHeap[T]) DecreaseKey(node Node[T], t T) error {
+	n, ok := node.(*FibonacciNode[T])
+	if !ok || n == nil || n.removed {
+		return ErrInvalidNode
+	}
+	if h.comparator(t, n.val) > 0 {
+		return ErrKeyIncreased
+	}
+	n.val = t
+	if p := n.parent; p != nil && h.comparator(n.val, p.val) < 0 {
+		h.cut(n, p)
+		h.cascadingCut(p)
+	}
+	if h.comparator(n.val, h.min.val) < 0 {
+		h.min = n
+	}
+	return nil
+}
+
+func (h *FibonacciHeap[T]) cut(n, parent *FibonacciNode[T]) {
+	h.removeFromList(n, &parent.child)
+	parent.degree--
+	n.parent = nil
+	n.marked = false
+	h.addRoot(n)
+}
+
+func (h *FibonacciHeap[T]) cascadingCut(n *FibonacciNode[T]) {
+	for p := n.parent; p != nil; n, p = p, p.parent {
+		if !n.marked {
+			n.marked = true
+			return
+		}
+		h.cut(n, p)
+	}
+}
+
+// consolidate 合并度数相同的根节点，直到每个度数最多只有一棵树，并且找出新的最小节点
+func (h *FibonacciHeap[T]) consolidate() {
+	roots := h.roots[:0]
+	for n, start := h.min, h.min; ; {
+		roots = append(roots, n)
+		n = n.right
+		if n == start {
+			break
+		}
+	}
+	// 度数最多是 O(log n)，按需扩展
+	byDegree := h.byDegree[:0]
+	for _, x := range roots {
+		x.left, x.right = x, x
+		for {
+			for len(byDegree) <= x.degree {
+				byDegree = append(byDegree, nil)
+			}
+			y := byDegree[x.degree]
+			if y == nil {
+				break
+			}
+			byDegree[x.degree] = nil
+			if h.comparator(y.val, x.val) < 0 {
+				x, y = y, x
+			}
+			// y 成为 x 的孩子
+			y.parent = x
+			y.marked = false
+			h.insertInto(&x.child, y)
+			x.degree++
+		}
+		byDegree[x.degree] = x
+	}
+	h.min = nil
+	for _, x := range byDegree {
+		if x != nil {
+			h.addRoot(x)
+		}
+	}
+	clear(roots)
+	clear(byDegree)
+	h.roots, h.byDegree = roots, byDegree
+}
+
+// addRoot 把单独的节点加入根链表，并且更新最小节点
+func (h *FibonacciHeap[T]) addRoot(n *FibonacciNode[T]) {
+	h.insertInto(&h.min, n)
+	if h.comparator(n.val, h.min.val) < 0 {
+		h.min = n
+	}
+}
+
+// insertInto 把单独的节点 n 插入到 *head 所在的循环链表中
+func (h *FibonacciHeap[T]) insertInto(head **FibonacciNode[T], n *FibonacciNode[T]) {
+	if *head == nil {
+		n.left, n.right = n, n
+		*head = n
+		return
+	}
+	r := (*head).right
+	n.left, n.right = *head, r
+	(*head).right = n
+	r.left = n
+}
+
+// removeFromList 把 n 从 *head 所在的循环链表中摘下来，如果 n 就是 *head，那么 *head 指向它的下一个节点
+func (h *FibonacciHeap[T]) removeFromList(n *FibonacciNode[T], head **FibonacciNode[T]) {
+	if n.right == n {
+		*head = nil
+	} else {
+		n.left.right = n.right
+		n.right.left = n.left
+		if *head == n {
+			*head = n.right
+		}
+	}
+	n.left, n.right = n, n
+}
@@ -0,0 +1,220 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heap
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/igevin/algokit/collection/queue"
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+)
+
+// priorityQueueHeap 把 queue.PriorityQueue 包装成 Heap，作为对比的基准
+type priorityQueueHeap[T any] struct {
+	*queue.PriorityQueue[T]
+}
+
+func (p priorityQueueHeap[T]) Push(t T) {
+	_ = p.Enqueue(t)
+}
+
+func (p priorityQueueHeap[T]) Pop() (T, error) {
+	return p.Dequeue()
+}
+
+func (p priorityQueueHeap[T]) Meld(other Heap[T]) {
+	drain[T](p, other)
+}
+
+type namedHeap[T any] struct {
+	name    string
+	newHeap func() Heap[T]
+}
+
+// benchmarkHeaps 参与对比的堆，第一个是作为基准的 queue.PriorityQueue
+func benchmarkHeaps[T any](compare comparator.Compare[T]) []namedHeap[T] {
+	return []namedHeap[T]{
+		{name: "PriorityQueue", newHeap: func() Heap[T] {
+			return priorityQueueHeap[T]{queue.NewPriorityQueue(0, compare)}
+		}},
+		{name: "binary", newHeap: func() Heap[T] { return NewDaryHeap(2, compare) }},
+		{name: "4-ary", newHeap: func() Heap[T] { return NewDaryHeap(4, compare) }},
+		{name: "8-ary", newHeap: func() Heap[T] { return NewDaryHeap(8, compare) }},
+		{name: "pairing", newHeap: func() Heap[T] { return NewPairingHeap(compare) }},
+		{name: "fibonacci", newHeap: func() Heap[T] { return NewFibonacciHeap(compare) }},
+	}
+}
+
+func addressableHeaps[T any](compare comparator.Compare[T]) map[string]func() AddressableHeap[T] {
+	return map[string]func() AddressableHeap[T]{
+		"pairing":   func() AddressableHeap[T] { return NewPairingHeap(compare) },
+		"fibonacci": func() AddressableHeap[T] { return NewFibonacciHeap(compare) },
+	}
+}
+
+// BenchmarkHeap_Hold 事件模拟中常见的 hold 模型：堆的大小保持不变，每次弹出最早的事件，再放入一个更晚的事件
+func BenchmarkHeap_Hold(b *testing.B) {
+	const size = 10000
+	for _, bh := range benchmarkHeaps(comparator.PrimeComparator[int]) {
+		b.Run(bh.name, func(b *testing.B) {
+			r := rand.New(rand.NewSource(1))
+			h := bh.newHeap()
+			for i := 0; i < size; i++ {
+				h.Push(r.Intn(size))
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				v, _ := h.Pop()
+				h.Push(v + r.Intn(size))
+			}
+		})
+	}
+}
+
+// BenchmarkHeap_Sort 放入所有元素之后再全部弹出
+func BenchmarkHeap_Sort(b *testing.B) {
+	const size = 10000
+	r := rand.New(rand.NewSource(1))
+	vals := make([]int, size)
+	for i := range vals {
+		vals[i] = r.Int()
+	}
+	for _, bh := range benchmarkHeaps(comparator.PrimeComparator[int]) {
+		b.Run(bh.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				h := bh.newHeap()
+				for _, v := range vals {
+					h.Push(v)
+				}
+				for h.Len() > 0 {
+					_, _ = h.Pop()
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkHeap_Dijkstra 在随机稀疏图上计算单源最短路径
+// 支持 DecreaseKey 的堆直接修改距离，其余的堆放入重复的顶点，弹出的时候跳过过期的
+func BenchmarkHeap_Dijkstra(b *testing.B) {
+	const vertices, degree = 10000, 8
+	r := rand.New(rand.NewSource(1))
+	graph := make([][]edge, vertices)
+	for u := range graph {
+		for i := 0; i < degree; i++ {
+			graph[u] = append(graph[u], edge{to: r.Intn(vertices), weight: r.Intn(1000) + 1})
+		}
+	}
+	for _, bh := range benchmarkHeaps(compareVertex) {
+		b.Run(bh.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				lazyDijkstra(graph, bh.newHeap)
+			}
+		})
+	}
+	for name, newHeap := range addressableHeaps(compareVertex) {
+		b.Run(name+" DecreaseKey", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				decreaseKeyDijkstra(graph, newHeap)
+			}
+		})
+	}
+}
+
+type edge struct {
+	to, weight int
+}
+
+type vertex struct {
+	id, dist int
+}
+
+func compareVertex(a, b vertex) int {
+	return comparator.PrimeComparator(a.dist, b.dist)
+}
+
+func lazyDijkstra(graph [][]edge, newHeap func() Heap[vertex]) []int {
+	dist := make([]int, len(graph))
+	for i := range dist {
+		dist[i] = math.MaxInt
+	}
+	dist[0] = 0
+	h := newHeap()
+	h.Push(vertex{id: 0})
+	for h.Len() > 0 {
+		u, _ := h.Pop()
+		if u.dist > dist[u.id] {
+			continue
+		}
+		for _, e := range graph[u.id] {
+			if d := u.dist + e.weight; d < dist[e.to] {
+				dist[e.to] = d
+				h.Push(vertex{id: e.to, dist: d})
+			}
+		}
+	}
+	return dist
+}
+
+func decreaseKeyDijkstra(graph [][]edge, newHeap func() AddressableHeap[vertex]) []int {
+	dist := make([]int, len(graph))
+	for i := range dist {
+		dist[i] = math.MaxInt
+	}
+	dist[0] = 0
+	nodes := make([]Node[vertex], len(graph))
+	h := newHeap()
+	nodes[0] = h.Insert(vertex{id: 0})
+	for h.Len() > 0 {
+		u, _ := h.Pop()
+		nodes[u.id] = nil
+		for _, e := range graph[u.id] {
+			d := u.dist + e.weight
+			if d >= dist[e.to] {
+				continue
+			}
+			dist[e.to] = d
+			if nodes[e.to] != nil {
+				_ = h.DecreaseKey(nodes[e.to], vertex{id: e.to, dist: d})
+			} else {
+				nodes[e.to] = h.Insert(vertex{id: e.to, dist: d})
+			}
+		}
+	}
+	return dist
+}
+
+// TestDijkstra 两种 Dijkstra 的实现在所有的堆上结果都一致，保证基准测试比较的是同一件事
+func TestDijkstra(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(2))
+	graph := make([][]edge, 500)
+	for u := range graph {
+		for i := 0; i < 4; i++ {
+			graph[u] = append(graph[u], edge{to: r.Intn(len(graph)), weight: r.Intn(100) + 1})
+		}
+	}
+	heaps := benchmarkHeaps(compareVertex)
+	want := lazyDijkstra(graph, heaps[0].newHeap)
+	for _, bh := range heaps[1:] {
+		assert.Equal(t, want, lazyDijkstra(graph, bh.newHeap), bh.name)
+	}
+	for name, newHeap := range addressableHeaps(compareVertex) {
+		assert.Equal(t, want, decreaseKeyDijkstra(graph, newHeap), name)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heap
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// heapFactories 所有的堆都要满足相同的行为
+var heapFactories = map[string]func() Heap[int]{
+	"binary": func() Heap[int] {
+		return NewDaryHeap(2, comparator.PrimeComparator[int])
+	},
+	"4-ary": func() Heap[int] {
+		return NewDaryHeap(4, comparator.PrimeComparator[int])
+	},
+	"pairing": func() Heap[int] {
+		return NewPairingHeap(comparator.PrimeComparator[int])
+	},
+	"fibonacci": func() Heap[int] {
+		return NewFibonacciHeap(comparator.PrimeComparator[int])
+	},
+}
+
+var addressableFactories = map[string]func() AddressableHeap[int]{
+	"pairing": func() AddressableHeap[int] {
+		return NewPairingHeap(comparator.PrimeComparator[int])
+	},
+	"fibonacci": func() AddressableHeap[int] {
+		return NewFibonacciHeap(comparator.PrimeComparator[int])
+	},
+}
+
+func TestHeap_PushPop(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		vals []int
+	}{
+		{name: "empty"},
+		{name: "single", vals: []int{1}},
+		{name: "sorted", vals: []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{name: "reversed", vals: []int{9, 8, 7, 6, 5, 4, 3, 2, 1}},
+		{name: "duplicated", vals: []int{3, 1, 3, 2, 1, 2, 3}},
+	}
+	for _, tc := range testCases {
+		for name, newHeap := range heapFactories {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				h := newHeap()
+				for _, v := range tc.vals {
+					h.Push(v)
+				}
+				assert.Equal(t, len(tc.vals), h.Len())
+				want := slices.Sorted(slices.Values(tc.vals))
+				assert.Equal(t, want, popAll(t, h))
+				_, err := h.Pop()
+				assert.Equal(t, ErrEmptyHeap, err)
+				_, err = h.Peek()
+				assert.Equal(t, ErrEmptyHeap, err)
+			})
+		}
+	}
+}
+
+// TestHeap_Random 交替地放入和弹出，每次都和排序之后的切片对比堆顶
+func TestHeap_Random(t *testing.T) {
+	t.Parallel()
+	for name, newHeap := range heapFactories {
+		t.Run(name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			h := newHeap()
+			var expected []int
+			for i := 0; i < 5000; i++ {
+				if r.Intn(3) > 0 || len(expected) == 0 {
+					v := r.Intn(1000)
+					h.Push(v)
+					expected = append(expected, v)
+					slices.Sort(expected)
+				} else {
+					v, err := h.Pop()
+					require.NoError(t, err)
+					require.Equal(t, expected[0], v)
+					expected = expected[1:]
+				}
+				require.Equal(t, len(expected), h.Len())
+				if len(expected) > 0 {
+					top, err := h.Peek()
+					require.NoError(t, err)
+					require.Equal(t, expected[0], top)
+				}
+			}
+			assert.Equal(t, expected, popAll(t, h))
+		})
+	}
+}
+
+func TestHeap_Meld(t *testing.T) {
+	t.Parallel()
+	for name, newHeap := range heapFactories {
+		for otherName, newOther := range heapFactories {
+			t.Run(name+"/"+otherName, func(t *testing.T) {
+				h, other := newHeap(), newOther()
+				for _, v := range []int{5, 1, 9} {
+					h.Push(v)
+				}
+				for _, v := range []int{4, 0, 7, 1} {
+					other.Push(v)
+				}
+				h.Meld(other)
+				assert.Equal(t, 0, other.Len())
+				assert.Equal(t, 7, h.Len())
+				// 合并自己没有任何效果
+				h.Meld(h)
+				// 合并空堆
+				h.Meld(newOther())
+				other.Push(3)
+				h.Meld(other)
+				assert.Equal(t, []int{0, 1, 1, 3, 4, 5, 7, 9}, popAll(t, h))
+			})
+		}
+		t.Run(name+"/into empty", func(t *testing.T) {
+			h, other := newHeap(), newHeap()
+			other.Push(2)
+			other.Push(1)
+			h.Meld(other)
+			assert.Equal(t, []int{1, 2}, popAll(t, h))
+		})
+	}
+}
+
+func TestAddressableHeap_DecreaseKey(t *testing.T) {
+	t.Parallel()
+	for name, newHeap := range addressableFactories {
+		t.Run(name, func(t *testing.T) {
+			h := newHeap()
+			nodes := make(map[int]Node[int])
+			for _, v := range []int{10, 20, 30, 40, 50, 60, 70} {
+				nodes[v] = h.Insert(v)
+			}
+			// 先弹出一次，让斐波那契堆整理出多层的树
+			v, err := h.Pop()
+			require.NoError(t, err)
+			assert.Equal(t, 10, v)
+
+			require.NoError(t, h.DecreaseKey(nodes[50], 5))
+			assert.Equal(t, 5, nodes[50].Value())
+			top, err := h.Peek()
+			require.NoError(t, err)
+			assert.Equal(t, 5, top)
+			// 值不变也是可以的
+			require.NoError(t, h.DecreaseKey(nodes[70], 70))
+			require.NoError(t, h.DecreaseKey(nodes[70], 25))
+
+			assert.Equal(t, ErrKeyIncreased, h.DecreaseKey(nodes[30], 31))
+			assert.Equal(t, ErrInvalidNode, h.DecreaseKey(nodes[10], 1))
+			assert.Equal(t, ErrInvalidNode, h.DecreaseKey(nil, 1))
+			assert.Equal(t, ErrInvalidNode, h.DecreaseKey(foreignNode{}, 1))
+
+			assert.Equal(t, []int{5, 20, 25, 30, 40, 60}, popAll(t, h))
+		})
+	}
+}
+
+// TestAddressableHeap_DecreaseKeyRandom 随机地放入、弹出、减小节点的值，和参照结果对比
+func TestAddressableHeap_DecreaseKeyRandom(t *testing.T) {
+	t.Parallel()
+	for name, newHeap := range addressableFactories {
+		t.Run(name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			h := newHeap()
+			var live []Node[int]
+			for i := 0; i < 20000; i++ {
+				switch op := r.Intn(10); {
+				case op < 5 || len(live) == 0:
+					live = append(live, h.Insert(r.Intn(1_000_000)))
+				case op < 8:
+					idx := r.Intn(len(live))
+					n := live[idx]
+					require.NoError(t, h.DecreaseKey(n, n.Value()-r.Intn(1000)))
+				default:
+					v, err := h.Pop()
+					require.NoError(t, err)
+					minIdx := 0
+					for j, n := range live {
+						if n.Value() < live[minIdx].Value() {
+							minIdx = j
+						}
+					}
+					require.Equal(t, live[minIdx].Value(), v)
+					// 值相同的时候弹出的不一定是同一个节点，找到真正被弹出的那一个
+					for j, n := range live {
+						if n.Value() == v && h.DecreaseKey(n, v) == ErrInvalidNode {
+							minIdx = j
+							break
+						}
+					}
+					live = slices.Delete(live, minIdx, minIdx+1)
+				}
+				require.Equal(t, len(live), h.Len())
+			}
+			want := make([]int, 0, len(live))
+			for _, n := range live {
+				want = append(want, n.Value())
+			}
+			slices.Sort(want)
+			assert.Equal(t, want, popAll(t, h))
+		})
+	}
+}
+
+func TestNewDaryHeapOf(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		d    int
+		vals []int
+	}{
+		{name: "empty", d: 3, vals: []int{}},
+		{name: "invalid arity", d: 0, vals: []int{3, 1, 2}},
+		{name: "ternary", d: 3, vals: []int{9, 3, 7, 1, 8, 2, 6, 4, 5, 0}},
+		{name: "wide", d: 16, vals: []int{9, 3, 7, 1, 8, 2, 6, 4, 5, 0}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := slices.Sorted(slices.Values(tc.vals))
+			h := NewDaryHeapOf(tc.d, slices.Clone(tc.vals), comparator.PrimeComparator[int])
+			assert.GreaterOrEqual(t, h.d, 2)
+			assert.Equal(t, want, popAll(t, h))
+		})
+	}
+}
+
+type foreignNode struct{}
+
+func (foreignNode) Value() int {
+	return 0
+}
+
+func popAll(t *testing.T, h Heap[int]) []int {
+	var res []int
+	for h.Len() > 0 {
+		v, err := h.Pop()
+		require.NoError(t, err)
+		res = append(res, v)
+	}
+	return res
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heap
+
+import "github.com/igevin/algokit/comparator"
+
+var _ AddressableHeap[any] = &PairingHeap[any]{}
+
+// PairingHeap 配对堆
+// Push、Meld 是 O(1) 的，Pop 均摊 O(log n)，DecreaseKey 均摊 o(log n)，
+// 实现简单，实际表现通常比斐波那契堆更好
+type PairingHeap[T any] struct {
+	comparator comparator.Compare[T]
+	root       *PairingNode[T]
+	size       int
+}
+
+// PairingNode 配对堆的节点
+// 孩子之间组成双向链表：prev 对于最左边的孩子指向父节点，对于其余的孩子指向左边的兄弟
+type PairingNode[T any] struct {
+	val     T
+	child   *PairingNode[T]
+	sibling *PairingNode[T]
+	prev    *PairingNode[T]
+	// removed 节点已经被弹出
+	removed bool
+}
+
+func (n *PairingNode[T]) Value() T {
+	return n.val
+}
+
+func NewPairingHeap[T any](compare comparator.Compare[T]) *PairingHeap[T] {
+	return &PairingHeap[T]{
+		comparator: compare,
+	}
+}
+
+func (h *PairingHeap[T]) Push(t T) {
+	h.Insert(t)
+}
+
+func (h *PairingHeap[T]) Insert(t T) Node[T] {
+	n := &PairingNode[T]{val: t}
+	h.root = h.link(h.root, n)
+	h.size++
+	return n
+}
+
+func (h *PairingHeap[T]) Pop() (T, error) {
+	if h.root == nil {
+		var t T
+		return t, ErrEmptyHeap
+	}
+	root := h.root
+	h.root = h.mergePairs(root.child)
+	if h.root != nil {
+		h.root.prev = nil
+	}
+	h.size--
+	root.child = nil
+	root.removed = true
+	return root.val, nil
+}
+
+func (h *PairingHeap[T]) Peek() (T, error) {
+	if h.root == nil {
+		var t T
+		return t, ErrEmptyHeap
+	}
+	return h.root.val, nil
+}
+
+func (h *PairingHeap[T]) Len() int {
+	return h.size
+}
+
+// Meld 合并另一个配对堆只需要比较一次两个根节点
+func (h *PairingHeap[T]) Meld(other Heap[T]) {
+	if other == Heap[T](h) {
+		return
+	}
+	o, ok := other.(*PairingHeap[T])
+	if !ok {
+		drain[T](h, other)
+		return
+	}
+	h.root = h.link(h.root, o.root)
+	h.size += o.size
+	o.root, o.size = nil, 0
+}
+
+// DecreaseKey 把以节点为根的子树剪下来，修改值之后再和根节点合并
+func (h *PairingHeap[T]) DecreaseKey(node Node[T], t T) error {
+	n, ok := node.(*PairingNode[T])
+	if !ok || n == nil || n.removed {
+		return ErrInvalidNode
+	}
+	if h.comparator(t, n.val) > 0 {
+		return ErrKeyIncreased
+	}
+	n.val = t
+	if n == h.root {
+		return nil
+	}
+	// 从兄弟链表中摘下来
+	if n.prev.child == n {
+		n.prev.child = n.sibling
+	} else {
+		n.prev.sibling = n.sibling
+	}
+	if n.sibling != nil {
+		n.sibling.prev = n.prev
+	}
+	n.prev, n.sibling = nil, nil
+	h.root = h.link(h.root, n)
+	return nil
+}
+
+// link 合并两棵树，值较大的根成为另一个根最左边的孩子
+func (h *PairingHeap[T]) link(a, b *PairingNode[T]) *PairingNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.comparator(b.val, a.val) < 0 {
+		a, b = b, a
+	}
+	b.prev = a
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	a.sibling = nil
+	return a
+}
+
+// mergePairs 两趟合并：先从左到右两两合并，再从右到左依次合并
+// 第一趟合并出来的树通过 sibling 反向串起来，第二趟直接从链表头开始，不需要额外的空间
+func (h *PairingHeap[T]) mergePairs(first *PairingNode[T]) *PairingNode[T] {
+	var reversed *PairingNode[T]
+	for first != nil {
+		a := first
+		b := a.sibling
+		if b != nil {
+			first = b.sibling
+			b.prev, b.sibling = nil, nil
+		} else {
+			first = nil
+		}
+		a.prev, a.sibling = nil, nil
+		pair := h.link(a, b)
+		pair.sibling = reversed
+		reversed = pair
+	}
+	var res *PairingNode[T]
+	for reversed != nil {
+		next := reversed.sibling
+		reversed.sibling = nil
+		res = h.link(res, reversed)
+		reversed = next
+	}
+	return res
+}
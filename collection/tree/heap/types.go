@@ -0,0 +1,52 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package heap
+
+// Heap 小顶堆，堆顶是按照 comparator.Compare 最小的元素
+type Heap[T any] interface {
+	// Push 放入元素
+	Push(t T)
+	// Pop 弹出堆顶的元素，堆为空的时候返回 ErrEmptyHeap
+	Pop() (T, error)
+	// Peek 返回堆顶的元素但是不弹出，堆为空的时候返回 ErrEmptyHeap
+	Peek() (T, error)
+	Len() int
+	// Meld 把 other 中的所有元素合并进来，合并之后 other 为空
+	// other 和自己是同一种堆的时候，实现可以利用内部结构快速合并
+	Meld(other Heap[T])
+}
+
+// AddressableHeap 支持 DecreaseKey 的堆
+type AddressableHeap[T any] interface {
+	Heap[T]
+	// Insert 和 Push 一样放入元素，返回的节点可以用于 DecreaseKey
+	Insert(t T) Node[T]
+	// DecreaseKey 把节点的值修改为一个更小的值 t
+	// 节点必须是这个堆（或者被合并进这个堆的堆）插入的
+	DecreaseKey(n Node[T], t T) error
+}
+
+// Node 可寻址堆中的节点
+type Node[T any] interface {
+	Value() T
+}
+
+// drain 弹出 other 中的所有元素放入 h，用于不同种类的堆之间的合并
+func drain[T any](h Heap[T], other Heap[T]) {
+	for other.Len() > 0 {
+		t, _ := other.Pop()
+		h.Push(t)
+	}
+}
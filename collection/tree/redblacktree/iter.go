@@ -0,0 +1,63 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+import "iter"
+
+// All 按照 key 升序遍历所有节点
+// 借助父节点指针寻找后继，每一步均摊 O(1)，不需要额外的栈
+// 迭代过程中如果增加或者删除了节点，迭代器会以 ErrRBTreeModified panic；
+// 通过 Set 修改 value 不影响迭代
+func (rb *RBTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		rb.walk(rb.firstNode(), rb.findSuccessor, func(*rbNode[K, V]) bool {
+			return true
+		}, yield)
+	}
+}
+
+// Backward 按照 key 降序遍历所有节点，其余和 All 一致
+func (rb *RBTree[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		rb.walk(rb.lastNode(), rb.findPredecessor, func(*rbNode[K, V]) bool {
+			return true
+		}, yield)
+	}
+}
+
+// Range 按照 key 升序遍历 [lo, hi) 范围内的节点，其余和 All 一致
+func (rb *RBTree[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		rb.walk(rb.ceilingNode(lo, true), rb.findSuccessor, func(node *rbNode[K, V]) bool {
+			return rb.compare(node.key, hi) < 0
+		}, yield)
+	}
+}
+
+// walk 从 start 开始，通过 next 逐个移动，直到 inRange 返回 false 或者 yield 要求停止
+func (rb *RBTree[K, V]) walk(start *rbNode[K, V],
+	next func(node *rbNode[K, V]) *rbNode[K, V],
+	inRange func(node *rbNode[K, V]) bool,
+	yield func(K, V) bool) {
+	expected := rb.modCount
+	for node := start; node != nil && inRange(node); node = next(node) {
+		if !yield(node.key, node.value) {
+			return
+		}
+		if rb.modCount != expected {
+			panic(ErrRBTreeModified)
+		}
+	}
+}
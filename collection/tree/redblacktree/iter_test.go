@@ -0,0 +1,169 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRBTree_Iterators(t *testing.T) {
+	keys := []int{50, 20, 80, 10, 30, 70, 90, 60, 40}
+	testCases := []struct {
+		name string
+		keys []int
+		seq  func(rb *RBTree[int, int]) iter.Seq2[int, int]
+		want []int
+	}{
+		{
+			name: "all empty",
+			seq: func(rb *RBTree[int, int]) iter.Seq2[int, int] {
+				return rb.All()
+			},
+			want: []int{},
+		},
+		{
+			name: "all",
+			keys: keys,
+			seq: func(rb *RBTree[int, int]) iter.Seq2[int, int] {
+				return rb.All()
+			},
+			want: []int{10, 20, 30, 40, 50, 60, 70, 80, 90},
+		},
+		{
+			name: "backward",
+			keys: keys,
+			seq: func(rb *RBTree[int, int]) iter.Seq2[int, int] {
+				return rb.Backward()
+			},
+			want: []int{90, 80, 70, 60, 50, 40, 30, 20, 10},
+		},
+		{
+			name: "range exist bounds",
+			keys: keys,
+			seq: func(rb *RBTree[int, int]) iter.Seq2[int, int] {
+				return rb.Range(30, 70)
+			},
+			want: []int{30, 40, 50, 60},
+		},
+		{
+			name: "range between bounds",
+			keys: keys,
+			seq: func(rb *RBTree[int, int]) iter.Seq2[int, int] {
+				return rb.Range(25, 75)
+			},
+			want: []int{30, 40, 50, 60, 70},
+		},
+		{
+			name: "range empty",
+			keys: keys,
+			seq: func(rb *RBTree[int, int]) iter.Seq2[int, int] {
+				return rb.Range(41, 49)
+			},
+			want: []int{},
+		},
+		{
+			name: "range reversed bounds",
+			keys: keys,
+			seq: func(rb *RBTree[int, int]) iter.Seq2[int, int] {
+				return rb.Range(70, 30)
+			},
+			want: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+			for _, k := range tc.keys {
+				require.NoError(t, rbTree.Add(k, k*10))
+			}
+			res := make([]int, 0, len(tc.want))
+			for k, v := range tc.seq(rbTree) {
+				assert.Equal(t, k*10, v)
+				res = append(res, k)
+			}
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
+
+func TestRBTree_IteratorBreak(t *testing.T) {
+	rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+	for i := 0; i < 10; i++ {
+		require.NoError(t, rbTree.Add(i, i))
+	}
+	res := make([]int, 0, 3)
+	for k := range rbTree.Backward() {
+		if len(res) == 3 {
+			break
+		}
+		res = append(res, k)
+	}
+	assert.Equal(t, []int{9, 8, 7}, res)
+}
+
+func TestRBTree_IteratorModified(t *testing.T) {
+	testCases := []struct {
+		name      string
+		modify    func(rb *RBTree[int, int], k int)
+		wantPanic bool
+	}{
+		{
+			name: "add",
+			modify: func(rb *RBTree[int, int], k int) {
+				_ = rb.Add(k+100, k)
+			},
+			wantPanic: true,
+		},
+		{
+			name: "delete",
+			modify: func(rb *RBTree[int, int], k int) {
+				_ = rb.Delete(k)
+			},
+			wantPanic: true,
+		},
+		{
+			name: "set",
+			modify: func(rb *RBTree[int, int], k int) {
+				_ = rb.Set(k, k*2)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+			for i := 0; i < 10; i++ {
+				require.NoError(t, rbTree.Add(i, i))
+			}
+			iterate := func() {
+				for k := range rbTree.All() {
+					tc.modify(rbTree, k)
+				}
+			}
+			if tc.wantPanic {
+				assert.PanicsWithValue(t, ErrRBTreeModified, iterate)
+				return
+			}
+			assert.NotPanics(t, iterate)
+			for k, v := range rbTree.All() {
+				assert.Equal(t, k*2, v)
+			}
+		})
+	}
+}
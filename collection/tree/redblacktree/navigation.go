@@ -0,0 +1,108 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+// First 返回最小的节点
+func (rb *RBTree[K, V]) First() (K, V, error) {
+	return rb.nodeResult(rb.firstNode())
+}
+
+// Last 返回最大的节点
+func (rb *RBTree[K, V]) Last() (K, V, error) {
+	return rb.nodeResult(rb.lastNode())
+}
+
+// Floor 返回小于等于 key 的最大节点
+func (rb *RBTree[K, V]) Floor(key K) (K, V, error) {
+	return rb.nodeResult(rb.floorNode(key, true))
+}
+
+// Ceiling 返回大于等于 key 的最小节点
+func (rb *RBTree[K, V]) Ceiling(key K) (K, V, error) {
+	return rb.nodeResult(rb.ceilingNode(key, true))
+}
+
+// Lower 返回严格小于 key 的最大节点
+func (rb *RBTree[K, V]) Lower(key K) (K, V, error) {
+	return rb.nodeResult(rb.floorNode(key, false))
+}
+
+// Higher 返回严格大于 key 的最小节点
+func (rb *RBTree[K, V]) Higher(key K) (K, V, error) {
+	return rb.nodeResult(rb.ceilingNode(key, false))
+}
+
+func (rb *RBTree[K, V]) nodeResult(node *rbNode[K, V]) (K, V, error) {
+	if node == nil {
+		var k K
+		var v V
+		return k, v, ErrRBTreeNodeNotFound
+	}
+	return node.key, node.value, nil
+}
+
+func (rb *RBTree[K, V]) firstNode() *rbNode[K, V] {
+	node := rb.root
+	for node != nil && node.left != nil {
+		node = node.left
+	}
+	return node
+}
+
+func (rb *RBTree[K, V]) lastNode() *rbNode[K, V] {
+	node := rb.root
+	for node != nil && node.right != nil {
+		node = node.right
+	}
+	return node
+}
+
+// floorNode 返回小于等于（inclusive 为 false 时是严格小于）key 的最大节点
+func (rb *RBTree[K, V]) floorNode(key K, inclusive bool) *rbNode[K, V] {
+	var res *rbNode[K, V]
+	node := rb.root
+	for node != nil {
+		cmp := rb.compare(key, node.key)
+		if cmp > 0 || (cmp == 0 && inclusive) {
+			res = node
+			if cmp == 0 {
+				return res
+			}
+			node = node.right
+		} else {
+			node = node.left
+		}
+	}
+	return res
+}
+
+// ceilingNode 返回大于等于（inclusive 为 false 时是严格大于）key 的最小节点
+func (rb *RBTree[K, V]) ceilingNode(key K, inclusive bool) *rbNode[K, V] {
+	var res *rbNode[K, V]
+	node := rb.root
+	for node != nil {
+		cmp := rb.compare(key, node.key)
+		if cmp < 0 || (cmp == 0 && inclusive) {
+			res = node
+			if cmp == 0 {
+				return res
+			}
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return res
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+import (
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRBTree_Navigation(t *testing.T) {
+	rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+	for _, k := range []int{50, 20, 80, 10, 30, 70, 90} {
+		require.NoError(t, rbTree.Add(k, k*10))
+	}
+	testCases := []struct {
+		name    string
+		nav     func(key int) (int, int, error)
+		key     int
+		wantKey int
+		wantErr error
+	}{
+		{name: "floor exist", nav: rbTree.Floor, key: 30, wantKey: 30},
+		{name: "floor between", nav: rbTree.Floor, key: 35, wantKey: 30},
+		{name: "floor too small", nav: rbTree.Floor, key: 5, wantErr: ErrRBTreeNodeNotFound},
+		{name: "floor too large", nav: rbTree.Floor, key: 100, wantKey: 90},
+		{name: "ceiling exist", nav: rbTree.Ceiling, key: 70, wantKey: 70},
+		{name: "ceiling between", nav: rbTree.Ceiling, key: 55, wantKey: 70},
+		{name: "ceiling too large", nav: rbTree.Ceiling, key: 95, wantErr: ErrRBTreeNodeNotFound},
+		{name: "lower exist", nav: rbTree.Lower, key: 50, wantKey: 30},
+		{name: "lower between", nav: rbTree.Lower, key: 75, wantKey: 70},
+		{name: "lower first", nav: rbTree.Lower, key: 10, wantErr: ErrRBTreeNodeNotFound},
+		{name: "higher exist", nav: rbTree.Higher, key: 50, wantKey: 70},
+		{name: "higher between", nav: rbTree.Higher, key: 15, wantKey: 20},
+		{name: "higher last", nav: rbTree.Higher, key: 90, wantErr: ErrRBTreeNodeNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			k, v, err := tc.nav(tc.key)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantKey, k)
+			assert.Equal(t, tc.wantKey*10, v)
+		})
+	}
+}
+
+func TestRBTree_FirstLast(t *testing.T) {
+	rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+	_, _, err := rbTree.First()
+	assert.Equal(t, ErrRBTreeNodeNotFound, err)
+	_, _, err = rbTree.Last()
+	assert.Equal(t, ErrRBTreeNodeNotFound, err)
+
+	for _, k := range []int{3, 1, 4, 5, 9, 2, 6} {
+		require.NoError(t, rbTree.Add(k, k))
+	}
+	k, _, err := rbTree.First()
+	require.NoError(t, err)
+	assert.Equal(t, 1, k)
+	k, _, err = rbTree.Last()
+	require.NoError(t, err)
+	assert.Equal(t, 9, k)
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+// Rank 返回严格小于 key 的节点数量，key 本身不需要存在于树中
+// 依赖每个节点维护的子树大小，时间复杂度 O(log n)
+func (rb *RBTree[K, V]) Rank(key K) int {
+	rank := 0
+	node := rb.root
+	for node != nil {
+		if rb.compare(key, node.key) <= 0 {
+			node = node.left
+		} else {
+			rank += node.left.getSize() + 1
+			node = node.right
+		}
+	}
+	return rank
+}
+
+// Select 返回第 k 小的节点，k 从 0 开始
+// 如果 k 超出范围，返回 ErrRBTreeIndexOutOfRange
+func (rb *RBTree[K, V]) Select(k int) (K, V, error) {
+	if k < 0 || k >= rb.Size() {
+		var key K
+		var v V
+		return key, v, ErrRBTreeIndexOutOfRange
+	}
+	node := rb.root
+	for {
+		leftSize := node.left.getSize()
+		switch {
+		case k < leftSize:
+			node = node.left
+		case k > leftSize:
+			k -= leftSize + 1
+			node = node.right
+		default:
+			return node.key, node.value, nil
+		}
+	}
+}
+
+// CountRange 返回 key 在 [lo, hi) 范围内的节点数量，和 Range 的范围一致
+func (rb *RBTree[K, V]) CountRange(lo, hi K) int {
+	if rb.compare(lo, hi) >= 0 {
+		return 0
+	}
+	return rb.Rank(hi) - rb.Rank(lo)
+}
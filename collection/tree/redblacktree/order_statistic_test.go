@@ -0,0 +1,101 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRBTree_OrderStatistic(t *testing.T) {
+	rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+	for _, k := range []int{50, 20, 80, 10, 30, 70, 90} {
+		require.NoError(t, rbTree.Add(k, k*10))
+	}
+	testCases := []struct {
+		name     string
+		key      int
+		wantRank int
+	}{
+		{name: "smaller than all", key: 5, wantRank: 0},
+		{name: "first", key: 10, wantRank: 0},
+		{name: "exist", key: 50, wantRank: 3},
+		{name: "between", key: 55, wantRank: 4},
+		{name: "last", key: 90, wantRank: 6},
+		{name: "larger than all", key: 95, wantRank: 7},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantRank, rbTree.Rank(tc.key))
+		})
+	}
+
+	k, v, err := rbTree.Select(3)
+	require.NoError(t, err)
+	assert.Equal(t, 50, k)
+	assert.Equal(t, 500, v)
+	_, _, err = rbTree.Select(-1)
+	assert.Equal(t, ErrRBTreeIndexOutOfRange, err)
+	_, _, err = rbTree.Select(7)
+	assert.Equal(t, ErrRBTreeIndexOutOfRange, err)
+
+	assert.Equal(t, 3, rbTree.CountRange(20, 70))
+	assert.Equal(t, 4, rbTree.CountRange(15, 75))
+	assert.Equal(t, 0, rbTree.CountRange(70, 20))
+	assert.Equal(t, 0, rbTree.CountRange(31, 49))
+}
+
+// TestRBTree_OrderStatisticRandom 随机增删之后，和排好序的切片交叉验证
+func TestRBTree_OrderStatisticRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+	keys := make([]int, 0, 512)
+	for i := 0; i < 2000; i++ {
+		k := r.Intn(1000)
+		pos, found := slices.BinarySearch(keys, k)
+		if r.Intn(3) == 0 {
+			require.NoError(t, rbTree.Delete(k))
+			if found {
+				keys = slices.Delete(keys, pos, pos+1)
+			}
+		} else if !found {
+			require.NoError(t, rbTree.Add(k, k))
+			keys = slices.Insert(keys, pos, k)
+		}
+		require.Equal(t, len(keys), rbTree.Size())
+		require.Equal(t, len(keys), rbTree.root.getSize())
+
+		probe := r.Intn(1100) - 50
+		require.Equal(t, sort.SearchInts(keys, probe), rbTree.Rank(probe))
+		if len(keys) > 0 {
+			idx := r.Intn(len(keys))
+			sk, _, err := rbTree.Select(idx)
+			require.NoError(t, err)
+			require.Equal(t, keys[idx], sk)
+		}
+		lo, hi := r.Intn(1000), r.Intn(1000)
+		want := 0
+		if lo < hi {
+			want = sort.SearchInts(keys, hi) - sort.SearchInts(keys, lo)
+		}
+		require.Equal(t, want, rbTree.CountRange(lo, hi))
+	}
+}
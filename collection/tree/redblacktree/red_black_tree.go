@@ -28,8 +28,15 @@ const (
 )
 
 var (
-	ErrRBTreeSameNode     = errors.New("algokit: RBTree不能添加重复节点Key")
-	ErrRBTreeNodeNotFound = errors.New("algokit: RBTree不存在节点Key")
+	ErrRBTreeSameNode        = errors.New("algokit: RBTree不能添加重复节点Key")
+	ErrRBTreeNodeNotFound    = errors.New("algokit: RBTree不存在节点Key")
+	ErrRBTreeIndexOutOfRange = errors.New("algokit: RBTree下标超出范围")
+	// ErrRBTreeInvalid Validate 发现红黑树的性质被破坏
+	ErrRBTreeInvalid            = errors.New("algokit: RBTree不满足红黑树性质")
+	ErrRBTreeUnordered          = errors.New("algokit: RBTree的key没有严格递增")
+	ErrRBTreeKeysValuesMismatch = errors.New("algokit: RBTree的key和value数量不一致")
+	// ErrRBTreeModified 迭代过程中红黑树的结构被修改，迭代器会以此 panic
+	ErrRBTreeModified = errors.New("algokit: RBTree在迭代过程中被修改")
 	// errRBTreeCantRepaceNil = errors.New("algokit: RBTree不能将节点替换为nil")
 )
 
@@ -37,6 +44,8 @@ type RBTree[K any, V any] struct {
 	root    *rbNode[K, V]
 	compare comparator.Compare[K]
 	size    int
+	// modCount 记录结构修改（增加、删除节点）的次数，用于迭代时快速失败
+	modCount int
 }
 
 func (rb *RBTree[K, V]) Size() int {
@@ -51,6 +60,8 @@ type rbNode[K any, V any] struct {
 	key                 K
 	value               V
 	left, right, parent *rbNode[K, V]
+	// size 以该节点为根的子树的节点数量，用于顺序统计
+	size int
 }
 
 func (node *rbNode[K, V]) setNode(v V) {
@@ -114,9 +125,11 @@ func (rb *RBTree[K, V]) Set(key K, value V) error {
 
 // addNode 插入新节点
 func (rb *RBTree[K, V]) addNode(node *rbNode[K, V]) error {
+	node.size = 1
 	if rb.root == nil {
 		rb.root = node
 		rb.size++
+		rb.modCount++
 		return nil
 	}
 	t := rb.root
@@ -139,7 +152,11 @@ func (rb *RBTree[K, V]) addNode(node *rbNode[K, V]) error {
 	} else {
 		parent.right = node
 	}
+	for p := parent; p != nil; p = p.parent {
+		p.size++
+	}
 	rb.size++
+	rb.modCount++
 	//rb.fixAfterAdd(node)
 	return nil
 }
@@ -161,6 +178,12 @@ func (rb *RBTree[K, V]) deleteNode(node *rbNode[K, V]) {
 		node.value = s.value
 		node = s
 	}
+	// 真正被摘除的是 node，先把它从祖先的子树大小中扣掉；
+	// node 自身置 0，这样在摘除前的旋转中重新计算子树大小时不会把它算进去
+	for p := node.parent; p != nil; p = p.parent {
+		p.size--
+	}
+	node.size = 0
 	var replacement *rbNode[K, V]
 	// node节点只有一个非空子节点
 	if node.left != nil {
@@ -201,6 +224,7 @@ func (rb *RBTree[K, V]) deleteNode(node *rbNode[K, V]) {
 		}
 	}
 	rb.size--
+	rb.modCount++
 }
 
 // findSuccessor 寻找后继节点
@@ -227,6 +251,29 @@ func (rb *RBTree[K, V]) findSuccessor(node *rbNode[K, V]) *rbNode[K, V] {
 
 }
 
+// findPredecessor 寻找前驱节点，和 findSuccessor 对称
+// case1: node节点存在左子节点,则左子树的最大节点是node的前驱节点
+// case2: node节点不存在左子节点,则其第一个为右节点的祖先的父节点为node的前驱节点
+func (rb *RBTree[K, V]) findPredecessor(node *rbNode[K, V]) *rbNode[K, V] {
+	if node == nil {
+		return nil
+	}
+	if node.left != nil {
+		p := node.left
+		for p.right != nil {
+			p = p.right
+		}
+		return p
+	}
+	p := node.parent
+	ch := node
+	for p != nil && ch == p.left {
+		ch = p
+		p = p.parent
+	}
+	return p
+}
+
 func (rb *RBTree[K, V]) findNode(key K) *rbNode[K, V] {
 	node := rb.root
 	for node != nil {
@@ -420,6 +467,8 @@ func (rb *RBTree[K, V]) rotateLeft(node *rbNode[K, V]) {
 	}
 	r.left = node
 	node.parent = r
+	r.size = node.size
+	node.updateSize()
 
 }
 
@@ -449,7 +498,21 @@ func (rb *RBTree[K, V]) rotateRight(node *rbNode[K, V]) {
 	}
 	l.right = node
 	node.parent = l
+	l.size = node.size
+	node.updateSize()
+
+}
+
+func (node *rbNode[K, V]) getSize() int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
 
+// updateSize 根据左右子树重新计算子树大小
+func (node *rbNode[K, V]) updateSize() {
+	node.size = node.left.getSize() + node.right.getSize() + 1
 }
 
 func (node *rbNode[K, V]) getColor() color {
@@ -0,0 +1,204 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+import "github.com/igevin/algokit/comparator"
+
+// NewRBTreeFromSorted 用严格递增的 keys 和对应的 values 构建红黑树，时间复杂度 O(n)
+// 每次取中点作为子树的根，这样除最底层外每一层都是满的，
+// 把不满的最底层染成红色，其余染成黑色，就能满足红黑树的所有性质，不需要任何旋转
+func NewRBTreeFromSorted[K any, V any](compare comparator.Compare[K], keys []K, values []V) (*RBTree[K, V], error) {
+	if len(keys) != len(values) {
+		return nil, ErrRBTreeKeysValuesMismatch
+	}
+	for i := 1; i < len(keys); i++ {
+		if compare(keys[i-1], keys[i]) >= 0 {
+			return nil, ErrRBTreeUnordered
+		}
+	}
+	// 满的层数，如果 n+1 是 2 的幂，那么是一棵满二叉树，没有红色节点
+	fullLevels := 0
+	for 1<<(fullLevels+1) <= len(keys)+1 {
+		fullLevels++
+	}
+	rb := NewRBTree[K, V](compare)
+	rb.root = buildSorted(keys, values, 0, fullLevels)
+	rb.size = len(keys)
+	return rb, nil
+}
+
+func buildSorted[K any, V any](keys []K, values []V, depth, redDepth int) *rbNode[K, V] {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	node := newRBNode(keys[mid], values[mid])
+	if depth != redDepth {
+		node.color = Black
+	}
+	node.left = buildSorted(keys[:mid], values[:mid], depth+1, redDepth)
+	node.right = buildSorted(keys[mid+1:], values[mid+1:], depth+1, redDepth)
+	if node.left != nil {
+		node.left.parent = node
+	}
+	if node.right != nil {
+		node.right.parent = node
+	}
+	node.updateSize()
+	return node
+}
+
+// Split 把红黑树拆分为两棵，左边的 key 都小于 key，右边的 key 都大于等于 key
+// 拆分之后 rb 会被清空，原有的节点转移到返回的两棵树中，时间复杂度 O(log² n)
+func (rb *RBTree[K, V]) Split(key K) (*RBTree[K, V], *RBTree[K, V]) {
+	l, r := rb.split(rb.root, key)
+	left, right := rb.withRoot(l), rb.withRoot(r)
+	rb.clear()
+	return left, right
+}
+
+// Join 拼接两棵红黑树，要求 left 的 key 都小于 right 的 key，否则返回 ErrRBTreeUnordered
+// 拼接之后 left 和 right 都会被清空，结果使用 left 的 compare，时间复杂度 O(log n)
+func Join[K any, V any](left, right *RBTree[K, V]) (*RBTree[K, V], error) {
+	if left.Size() == 0 || right.Size() == 0 {
+		res := left.withRoot(left.root)
+		if left.Size() == 0 {
+			res.root = right.root
+			res.size = right.Size()
+		}
+		left.clear()
+		right.clear()
+		return res, nil
+	}
+	if left.compare(left.lastNode().key, right.firstNode().key) >= 0 {
+		return nil, ErrRBTreeUnordered
+	}
+	// 取出右边最小的节点作为中间节点，它没有左子节点，所以 deleteNode 摘除的就是它本身
+	mid := right.firstNode()
+	right.deleteNode(mid)
+	res := left.withRoot(left.join3(left.root, mid, right.root))
+	left.clear()
+	right.clear()
+	return res, nil
+}
+
+// split 把以 node 为根的子树拆分为小于 key 和大于等于 key 的两部分
+func (rb *RBTree[K, V]) split(node *rbNode[K, V], key K) (*rbNode[K, V], *rbNode[K, V]) {
+	if node == nil {
+		return nil, nil
+	}
+	l, r := node.left, node.right
+	if rb.compare(key, node.key) <= 0 {
+		ll, lr := rb.split(l, key)
+		return ll, rb.join3(lr, node, r)
+	}
+	rl, rr := rb.split(r, key)
+	return rb.join3(l, node, rl), rr
+}
+
+// join3 把 l、m、r 拼接为一棵红黑树，要求 l 的 key 都小于 m，r 的 key 都大于 m
+// l 和 r 的根如果是红色会先染黑，这不会破坏它们自身的红黑树性质
+// 沿着较高一侧的边界往下找到黑高和另一侧相同的黑色节点，用 m 替换它，
+// 之后的处理和插入一个红色节点完全一致，复用 fixAfterAdd
+func (rb *RBTree[K, V]) join3(l, m, r *rbNode[K, V]) *rbNode[K, V] {
+	m.left, m.right, m.parent = nil, nil, nil
+	m.color = Red
+	for _, child := range []*rbNode[K, V]{l, r} {
+		if child != nil {
+			child.parent = nil
+			child.color = Black
+		}
+	}
+	lh, rh := blackHeight(l), blackHeight(r)
+	if lh == rh {
+		m.left, m.right = l, r
+		m.color = Black
+		m.linkChildren()
+		m.updateSize()
+		return m
+	}
+
+	higher, lower, targetHeight := l, r, rh
+	if lh < rh {
+		higher, lower, targetHeight = r, l, lh
+	}
+	tree := &RBTree[K, V]{root: higher, compare: rb.compare}
+	var parent *rbNode[K, V]
+	c, h := higher, blackHeight(higher)
+	for c.isRed() || h != targetHeight {
+		if c.isBlack() {
+			h--
+		}
+		parent = c
+		if lh > rh {
+			c = c.right
+		} else {
+			c = c.left
+		}
+	}
+	if lh > rh {
+		m.left, m.right = c, lower
+		parent.right = m
+	} else {
+		m.left, m.right = lower, c
+		parent.left = m
+	}
+	m.parent = parent
+	m.linkChildren()
+	m.updateSize()
+	for p := parent; p != nil; p = p.parent {
+		p.updateSize()
+	}
+	tree.fixAfterAdd(m)
+	return tree.root
+}
+
+// withRoot 用 root 构建一棵和 rb 使用相同 compare 的新树
+func (rb *RBTree[K, V]) withRoot(root *rbNode[K, V]) *RBTree[K, V] {
+	res := NewRBTree[K, V](rb.compare)
+	if root != nil {
+		root.parent = nil
+		root.color = Black
+		res.root = root
+		res.size = root.size
+	}
+	return res
+}
+
+func (rb *RBTree[K, V]) clear() {
+	rb.root = nil
+	rb.size = 0
+	rb.modCount++
+}
+
+// blackHeight 从 node 到叶子路径上的黑色节点数量，不包含 nil 叶子
+func blackHeight[K any, V any](node *rbNode[K, V]) int {
+	h := 0
+	for ; node != nil; node = node.left {
+		if node.isBlack() {
+			h++
+		}
+	}
+	return h
+}
+
+func (node *rbNode[K, V]) linkChildren() {
+	if node.left != nil {
+		node.left.parent = node
+	}
+	if node.right != nil {
+		node.right.parent = node
+	}
+}
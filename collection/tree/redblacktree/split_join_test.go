@@ -0,0 +1,296 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRBTreeFromSorted(t *testing.T) {
+	testCases := []struct {
+		name    string
+		keys    []int
+		values  []int
+		wantErr error
+	}{
+		{
+			name:   "empty",
+			keys:   []int{},
+			values: []int{},
+		},
+		{
+			name:   "single",
+			keys:   []int{1},
+			values: []int{10},
+		},
+		{
+			name:   "perfect",
+			keys:   []int{1, 2, 3, 4, 5, 6, 7},
+			values: []int{10, 20, 30, 40, 50, 60, 70},
+		},
+		{
+			name:   "incomplete last level",
+			keys:   []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			values: []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
+		},
+		{
+			name:    "mismatch",
+			keys:    []int{1, 2},
+			values:  []int{10},
+			wantErr: ErrRBTreeKeysValuesMismatch,
+		},
+		{
+			name:    "unordered",
+			keys:    []int{1, 3, 2},
+			values:  []int{10, 30, 20},
+			wantErr: ErrRBTreeUnordered,
+		},
+		{
+			name:    "duplicate",
+			keys:    []int{1, 2, 2},
+			values:  []int{10, 20, 20},
+			wantErr: ErrRBTreeUnordered,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rbTree, err := NewRBTreeFromSorted(comparator.PrimeComparator[int], tc.keys, tc.values)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			require.NoError(t, rbTree.Validate())
+			assert.Equal(t, len(tc.keys), rbTree.Size())
+			keys, values := collect(rbTree)
+			assert.Equal(t, tc.keys, keys)
+			assert.Equal(t, tc.values, values)
+		})
+	}
+
+	for n := 0; n < 300; n++ {
+		keys := sequence(0, n)
+		rbTree, err := NewRBTreeFromSorted(comparator.PrimeComparator[int], keys, keys)
+		require.NoError(t, err)
+		require.NoError(t, rbTree.Validate(), "n = %d", n)
+		// 构建出来的树要能继续正常增删
+		require.NoError(t, rbTree.Add(n, n))
+		require.NoError(t, rbTree.Delete(n/2))
+		require.NoError(t, rbTree.Validate(), "n = %d", n)
+	}
+}
+
+func TestRBTree_Split(t *testing.T) {
+	testCases := []struct {
+		name      string
+		keys      []int
+		key       int
+		wantLeft  []int
+		wantRight []int
+	}{
+		{
+			name:      "empty",
+			key:       1,
+			wantLeft:  []int{},
+			wantRight: []int{},
+		},
+		{
+			name:      "exist",
+			keys:      []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			key:       4,
+			wantLeft:  []int{1, 2, 3},
+			wantRight: []int{4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			name:      "between",
+			keys:      []int{10, 20, 30, 40, 50},
+			key:       35,
+			wantLeft:  []int{10, 20, 30},
+			wantRight: []int{40, 50},
+		},
+		{
+			name:      "smaller than all",
+			keys:      []int{10, 20, 30},
+			key:       5,
+			wantLeft:  []int{},
+			wantRight: []int{10, 20, 30},
+		},
+		{
+			name:      "larger than all",
+			keys:      []int{10, 20, 30},
+			key:       35,
+			wantLeft:  []int{10, 20, 30},
+			wantRight: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+			for _, k := range tc.keys {
+				require.NoError(t, rbTree.Add(k, k))
+			}
+			left, right := rbTree.Split(tc.key)
+			assert.Equal(t, 0, rbTree.Size())
+			require.NoError(t, left.Validate())
+			require.NoError(t, right.Validate())
+			leftKeys, _ := collect(left)
+			rightKeys, _ := collect(right)
+			assert.Equal(t, tc.wantLeft, leftKeys)
+			assert.Equal(t, tc.wantRight, rightKeys)
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	testCases := []struct {
+		name     string
+		left     []int
+		right    []int
+		wantKeys []int
+		wantErr  error
+	}{
+		{
+			name:     "both empty",
+			wantKeys: []int{},
+		},
+		{
+			name:     "left empty",
+			right:    []int{1, 2},
+			wantKeys: []int{1, 2},
+		},
+		{
+			name:     "right empty",
+			left:     []int{1, 2},
+			wantKeys: []int{1, 2},
+		},
+		{
+			name:     "same height",
+			left:     []int{1, 2, 3},
+			right:    []int{4, 5, 6},
+			wantKeys: []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "left higher",
+			left:     sequence(0, 100),
+			right:    []int{100, 101},
+			wantKeys: sequence(0, 102),
+		},
+		{
+			name:     "right higher",
+			left:     []int{0},
+			right:    sequence(1, 100),
+			wantKeys: sequence(0, 100),
+		},
+		{
+			name:    "overlap",
+			left:    []int{1, 5},
+			right:   []int{3, 7},
+			wantErr: ErrRBTreeUnordered,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			left := NewRBTree[int, int](comparator.PrimeComparator[int])
+			for _, k := range tc.left {
+				require.NoError(t, left.Add(k, k))
+			}
+			right := NewRBTree[int, int](comparator.PrimeComparator[int])
+			for _, k := range tc.right {
+				require.NoError(t, right.Add(k, k))
+			}
+			res, err := Join(left, right)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			require.NoError(t, res.Validate())
+			keys, _ := collect(res)
+			assert.Equal(t, tc.wantKeys, keys)
+			assert.Equal(t, 0, left.Size())
+			assert.Equal(t, 0, right.Size())
+		})
+	}
+}
+
+// TestRBTree_SplitJoinRandom 随机拆分再拼接，结果应该和原来的内容一致
+func TestRBTree_SplitJoinRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for round := 0; round < 200; round++ {
+		rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+		keys := make([]int, 0, 64)
+		for i := r.Intn(64); i > 0; i-- {
+			k := r.Intn(200)
+			if rbTree.Add(k, k) == nil {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		key := r.Intn(220) - 10
+		left, right := rbTree.Split(key)
+		require.NoError(t, left.Validate())
+		require.NoError(t, right.Validate())
+		for k := range left.All() {
+			require.Less(t, k, key)
+		}
+		for k := range right.All() {
+			require.GreaterOrEqual(t, k, key)
+		}
+		res, err := Join(left, right)
+		require.NoError(t, err)
+		require.NoError(t, res.Validate())
+		got, _ := collect(res)
+		require.Equal(t, keys, got)
+	}
+}
+
+func BenchmarkNewRBTreeFromSorted(b *testing.B) {
+	keys := sequence(0, 1<<16)
+	b.Run("Add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+			for _, k := range keys {
+				_ = rbTree.Add(k, k)
+			}
+		}
+	})
+	b.Run("FromSorted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = NewRBTreeFromSorted(comparator.PrimeComparator[int], keys, keys)
+		}
+	})
+}
+
+func collect[K any, V any](rb *RBTree[K, V]) ([]K, []V) {
+	keys := make([]K, 0, rb.Size())
+	values := make([]V, 0, rb.Size())
+	for k, v := range rb.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	return keys, values
+}
+
+func sequence(from, to int) []int {
+	res := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		res = append(res, i)
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+import "fmt"
+
+// Validate 检查红黑树的所有性质，返回的错误都包装了 ErrRBTreeInvalid
+// 1、BST 顺序：左子树的 key 都小于节点，右子树的 key 都大于节点
+// 2、根节点是黑色
+// 3、不存在连续的红色节点
+// 4、从任意节点到叶子的每条路径上黑色节点数量一致
+// 5、父节点指针和子节点指针互相一致
+// 6、子树大小和节点总数一致
+// 时间复杂度 O(n)，主要用于测试和排查问题
+func (rb *RBTree[K, V]) Validate() error {
+	if rb.root == nil {
+		if rb.size != 0 {
+			return fmt.Errorf("%w: 空树的 size 为 %d", ErrRBTreeInvalid, rb.size)
+		}
+		return nil
+	}
+	if rb.root.parent != nil {
+		return fmt.Errorf("%w: 根节点 %v 的父节点不为 nil", ErrRBTreeInvalid, rb.root.key)
+	}
+	if rb.root.isRed() {
+		return fmt.Errorf("%w: 根节点 %v 是红色", ErrRBTreeInvalid, rb.root.key)
+	}
+	if _, err := rb.validateNode(rb.root, nil, nil); err != nil {
+		return err
+	}
+	if rb.root.size != rb.size {
+		return fmt.Errorf("%w: 根节点子树大小 %d 和 size %d 不一致", ErrRBTreeInvalid, rb.root.size, rb.size)
+	}
+	return nil
+}
+
+// validateNode 检查以 node 为根的子树，key 必须在 (lo, hi) 范围内，nil 表示不限制
+// 返回子树的黑高
+func (rb *RBTree[K, V]) validateNode(node, lo, hi *rbNode[K, V]) (int, error) {
+	if node == nil {
+		return 1, nil
+	}
+	if lo != nil && rb.compare(node.key, lo.key) <= 0 {
+		return 0, fmt.Errorf("%w: 节点 %v 不大于 %v", ErrRBTreeInvalid, node.key, lo.key)
+	}
+	if hi != nil && rb.compare(node.key, hi.key) >= 0 {
+		return 0, fmt.Errorf("%w: 节点 %v 不小于 %v", ErrRBTreeInvalid, node.key, hi.key)
+	}
+	for _, child := range []*rbNode[K, V]{node.left, node.right} {
+		if child == nil {
+			continue
+		}
+		if child.parent != node {
+			return 0, fmt.Errorf("%w: 节点 %v 的父节点指针不指向 %v", ErrRBTreeInvalid, child.key, node.key)
+		}
+		if node.isRed() && child.isRed() {
+			return 0, fmt.Errorf("%w: 节点 %v 和 %v 是连续的红色节点", ErrRBTreeInvalid, node.key, child.key)
+		}
+	}
+	leftHeight, err := rb.validateNode(node.left, lo, node)
+	if err != nil {
+		return 0, err
+	}
+	rightHeight, err := rb.validateNode(node.right, node, hi)
+	if err != nil {
+		return 0, err
+	}
+	if leftHeight != rightHeight {
+		return 0, fmt.Errorf("%w: 节点 %v 左右黑高不一致 %d != %d", ErrRBTreeInvalid, node.key, leftHeight, rightHeight)
+	}
+	if size := node.left.getSize() + node.right.getSize() + 1; node.size != size {
+		return 0, fmt.Errorf("%w: 节点 %v 的子树大小为 %d，实际为 %d", ErrRBTreeInvalid, node.key, node.size, size)
+	}
+	if node.isBlack() {
+		leftHeight++
+	}
+	return leftHeight, nil
+}
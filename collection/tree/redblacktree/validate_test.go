@@ -0,0 +1,161 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redblacktree
+
+import (
+	"testing"
+
+	"github.com/igevin/algokit/comparator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRBTree_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		corrupt func(rb *RBTree[int, int])
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			corrupt: func(rb *RBTree[int, int]) {},
+		},
+		{
+			name: "empty",
+			corrupt: func(rb *RBTree[int, int]) {
+				rb.root = nil
+				rb.size = 0
+			},
+		},
+		{
+			name: "empty with size",
+			corrupt: func(rb *RBTree[int, int]) {
+				rb.root = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "red root",
+			corrupt: func(rb *RBTree[int, int]) {
+				rb.root.color = Red
+			},
+			wantErr: true,
+		},
+		{
+			name: "bst order",
+			corrupt: func(rb *RBTree[int, int]) {
+				rb.findNode(1).key = 100
+			},
+			wantErr: true,
+		},
+		{
+			name: "red red",
+			corrupt: func(rb *RBTree[int, int]) {
+				node := rb.findNode(8)
+				node.color = Red
+				node.parent.color = Red
+			},
+			wantErr: true,
+		},
+		{
+			name: "black height",
+			corrupt: func(rb *RBTree[int, int]) {
+				rb.findNode(1).color = Red
+			},
+			wantErr: true,
+		},
+		{
+			name: "parent pointer",
+			corrupt: func(rb *RBTree[int, int]) {
+				rb.findNode(1).parent = rb.root
+			},
+			wantErr: true,
+		},
+		{
+			name: "subtree size",
+			corrupt: func(rb *RBTree[int, int]) {
+				rb.findNode(1).size++
+			},
+			wantErr: true,
+		},
+		{
+			name: "tree size",
+			corrupt: func(rb *RBTree[int, int]) {
+				rb.size++
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+			for i := 1; i <= 8; i++ {
+				require.NoError(t, rbTree.Add(i, i))
+			}
+			require.NoError(t, rbTree.Validate())
+			tc.corrupt(rbTree)
+			err := rbTree.Validate()
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrRBTreeInvalid)
+		})
+	}
+}
+
+// FuzzRBTree 每两个字节构成一次操作：第一个字节决定增加、删除还是修改，第二个字节是 key
+// 每一步之后都校验红黑树的性质，并且和内置 map 对比内容
+func FuzzRBTree(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 1, 2, 2, 3})
+	f.Add([]byte{0, 5, 0, 4, 0, 3, 0, 2, 0, 1, 1, 5, 1, 4, 1, 3})
+	f.Add([]byte{0, 9, 0, 200, 0, 17, 0, 1, 0, 100, 1, 9, 2, 17, 1, 200})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		rbTree := NewRBTree[int, int](comparator.PrimeComparator[int])
+		model := make(map[int]int)
+		for i := 0; i+1 < len(ops); i += 2 {
+			key := int(ops[i+1])
+			_, exist := model[key]
+			switch ops[i] % 3 {
+			case 0:
+				err := rbTree.Add(key, i)
+				if exist {
+					require.Equal(t, ErrRBTreeSameNode, err)
+				} else {
+					require.NoError(t, err)
+					model[key] = i
+				}
+			case 1:
+				require.NoError(t, rbTree.Delete(key))
+				delete(model, key)
+			case 2:
+				err := rbTree.Set(key, i)
+				if exist {
+					require.NoError(t, err)
+					model[key] = i
+				} else {
+					require.Equal(t, ErrRBTreeNodeNotFound, err)
+				}
+			}
+			require.NoError(t, rbTree.Validate())
+			require.Equal(t, len(model), rbTree.Size())
+		}
+		for k, v := range model {
+			val, err := rbTree.Find(k)
+			require.NoError(t, err)
+			require.Equal(t, v, val)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import "time"
+
+// Clock 时钟的抽象，依赖时间的数据结构通过它获取当前时间和创建定时器
+// 测试的时候可以替换成 Fake，手动推进时间，不需要真的等待
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	// After 等价于 NewTimer(d).C()
+	After(d time.Duration) <-chan time.Time
+}
+
+// Timer 对应 time.Timer，语义也和它保持一致
+type Timer interface {
+	// C 返回到期之后发送时间的 channel
+	C() <-chan time.Time
+	// Stop 停止定时器，如果定时器已经到期或者已经停止了，返回 false
+	Stop() bool
+	// Reset 让定时器在 d 之后重新到期，如果定时器原本还在等待中，返回 true
+	Reset(d time.Duration) bool
+}
+
+// Real 返回基于 time 包的真实时钟
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{t: time.NewTimer(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r realTimer) Stop() bool {
+	return r.t.Stop()
+}
+
+func (r realTimer) Reset(d time.Duration) bool {
+	return r.t.Reset(d)
+}
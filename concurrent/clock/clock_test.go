@@ -0,0 +1,36 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReal(t *testing.T) {
+	t.Parallel()
+	c := Real()
+	start := c.Now()
+	timer := c.NewTimer(10 * time.Millisecond)
+	at := <-timer.C()
+	assert.GreaterOrEqual(t, at.Sub(start), 10*time.Millisecond)
+	assert.False(t, timer.Stop())
+	assert.False(t, timer.Reset(time.Hour))
+	assert.True(t, timer.Stop())
+	at = <-c.After(10 * time.Millisecond)
+	assert.GreaterOrEqual(t, at.Sub(start), 20*time.Millisecond)
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+var _ Clock = &Fake{}
+
+// Fake 手动推进的时钟，只有调用 Advance 或者 Set 的时候时间才会流逝
+// 到期的定时器会在 Advance 和 Set 返回之前把时间发送到 channel 里
+type Fake struct {
+	mutex sync.Mutex
+	now   time.Time
+	// timers 还在等待中的定时器
+	timers []*fakeTimer
+	// changed 在等待中的定时器数量变化之后关闭，用于 BlockUntil
+	changed chan struct{}
+}
+
+// NewFake 创建手动推进的时钟，初始时间为 now
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	t := &fakeTimer{
+		f: f,
+		c: make(chan time.Time, 1),
+	}
+	f.start(t, d)
+	return t
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Advance 把时间向前推进 d，并且触发所有到期的定时器
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.setLocked(f.now.Add(d))
+}
+
+// Set 把时间设置为 now，并且触发所有到期的定时器
+// now 早于当前时间的时候什么也不会发生
+func (f *Fake) Set(now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.setLocked(now)
+}
+
+// BlockUntil 阻塞直到至少有 n 个定时器在等待中
+// 用于确认被测试的 goroutine 已经开始等待，再去推进时间
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mutex.Lock()
+		if len(f.timers) >= n {
+			f.mutex.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mutex.Unlock()
+		<-changed
+	}
+}
+
+// Pending 返回等待中的定时器数量
+func (f *Fake) Pending() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.timers)
+}
+
+func (f *Fake) setLocked(now time.Time) {
+	if now.Before(f.now) {
+		return
+	}
+	f.now = now
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		if t.deadline.After(now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.active = false
+		// 和 time.Timer 一样，channel 的容量是 1，没有被取走的旧值会让新值被丢弃
+		select {
+		case t.c <- now:
+		default:
+		}
+	}
+	clear(f.timers[len(pending):])
+	f.timers = pending
+	f.notify()
+}
+
+func (f *Fake) start(t *fakeTimer, d time.Duration) {
+	t.deadline = f.now.Add(d)
+	if d <= 0 {
+		select {
+		case t.c <- f.now:
+		default:
+		}
+		return
+	}
+	t.active = true
+	f.timers = append(f.timers, t)
+	f.notify()
+}
+
+func (f *Fake) stop(t *fakeTimer) bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	for i, ft := range f.timers {
+		if ft == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			break
+		}
+	}
+	f.notify()
+	return true
+}
+
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+type fakeTimer struct {
+	f        *Fake
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.f.mutex.Lock()
+	defer t.f.mutex.Unlock()
+	return t.f.stop(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.f.mutex.Lock()
+	defer t.f.mutex.Unlock()
+	active := t.f.stop(t)
+	// 和 Go 1.23 之后的 time.Timer 一样，Reset 会丢弃还没有被取走的旧值
+	select {
+	case <-t.c:
+	default:
+	}
+	t.f.start(t, d)
+	return active
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake_Timer(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(100, 0)
+	testCases := []struct {
+		name    string
+		timer   time.Duration
+		advance []time.Duration
+
+		wantFired bool
+		wantAt    time.Time
+	}{
+		{
+			name:    "not yet",
+			timer:   time.Second,
+			advance: []time.Duration{999 * time.Millisecond},
+		},
+		{
+			name:      "exactly",
+			timer:     time.Second,
+			advance:   []time.Duration{time.Second},
+			wantFired: true,
+			wantAt:    start.Add(time.Second),
+		},
+		{
+			name:      "several steps",
+			timer:     time.Second,
+			advance:   []time.Duration{300 * time.Millisecond, 300 * time.Millisecond, time.Second},
+			wantFired: true,
+			wantAt:    start.Add(1600 * time.Millisecond),
+		},
+		{
+			name:      "not positive",
+			timer:     0,
+			wantFired: true,
+			wantAt:    start,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewFake(start)
+			timer := c.NewTimer(tc.timer)
+			for _, d := range tc.advance {
+				c.Advance(d)
+			}
+			select {
+			case at := <-timer.C():
+				assert.True(t, tc.wantFired)
+				assert.Equal(t, tc.wantAt, at)
+			default:
+				assert.False(t, tc.wantFired)
+			}
+		})
+	}
+}
+
+func TestFake_StopReset(t *testing.T) {
+	t.Parallel()
+	c := NewFake(time.Unix(0, 0))
+	timer := c.NewTimer(time.Second)
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	c.Advance(time.Hour)
+	assert.Empty(t, timer.C())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Reset(2*time.Second))
+	c.Advance(time.Second)
+	assert.Empty(t, timer.C())
+	c.Advance(time.Second)
+	assert.Len(t, timer.C(), 1)
+	// 已经到期的定时器 Reset 之后会丢弃旧值
+	assert.False(t, timer.Reset(time.Second))
+	assert.Empty(t, timer.C())
+}
+
+func TestFake_Set(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(0, 0)
+	c := NewFake(start)
+	timer := c.NewTimer(time.Minute)
+	// 时间不会倒流
+	c.Set(start.Add(-time.Hour))
+	assert.Equal(t, start, c.Now())
+	c.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), c.Now())
+	assert.Equal(t, start.Add(time.Hour), <-timer.C())
+}
+
+func TestFake_After(t *testing.T) {
+	t.Parallel()
+	c := NewFake(time.Unix(0, 0))
+	ch := c.After(time.Second)
+	c.Advance(time.Second - 1)
+	assert.Empty(t, ch)
+	c.Advance(1)
+	assert.Equal(t, time.Unix(1, 0), <-ch)
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	t.Parallel()
+	c := NewFake(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		timer := c.NewTimer(time.Second)
+		<-timer.C()
+	}()
+	c.BlockUntil(1)
+	assert.Equal(t, 1, c.Pending())
+	c.Advance(time.Second)
+	assert.Equal(t, 0, c.Pending())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timer not fired")
+	}
+	c.BlockUntil(0)
+}
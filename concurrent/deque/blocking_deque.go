@@ -0,0 +1,194 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deque
+
+import (
+	"context"
+	"sync"
+
+	"github.com/igevin/algokit/collection/deque"
+	"github.com/igevin/algokit/collection/queue"
+	"github.com/igevin/algokit/internal/syncx"
+)
+
+// BlockingDeque 并发安全的阻塞双端队列
+// capacity <= 0 时为无界队列，Put 永远不会阻塞
+// Put 系列方法在队列满的时候阻塞，Take 系列方法在队列空的时候阻塞，直到条件满足或者 ctx 结束
+// Offer 和 Poll 系列方法不阻塞，分别返回 queue.ErrOutOfCapacity 和 deque.ErrEmptyDeque
+type BlockingDeque[T any] struct {
+	d        *deque.ArrayDequeue[T]
+	capacity int
+	mutex    *sync.Mutex
+	// notEmpty 在放入元素之后广播，唤醒 Take
+	notEmpty *syncx.Cond
+	// notFull 在取出元素之后广播，唤醒 Put
+	notFull *syncx.Cond
+}
+
+// NewBlockingDeque 创建阻塞双端队列，capacity <= 0 时为无界队列
+// capacity 只是上限，缓冲区从很小开始按需扩缩容，所以可以放心地设置一个很大的上限
+func NewBlockingDeque[T any](capacity int) *BlockingDeque[T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	m := &sync.Mutex{}
+	return &BlockingDeque[T]{
+		d:        deque.NewArrayDequeue[T](0),
+		capacity: capacity,
+		mutex:    m,
+		notEmpty: syncx.NewCond(m),
+		notFull:  syncx.NewCond(m),
+	}
+}
+
+// PutFirst 在队首放入元素，队列满的时候阻塞
+func (b *BlockingDeque[T]) PutFirst(ctx context.Context, t T) error {
+	return b.put(ctx, t, b.d.AddFirst)
+}
+
+// PutLast 在队尾放入元素，队列满的时候阻塞
+func (b *BlockingDeque[T]) PutLast(ctx context.Context, t T) error {
+	return b.put(ctx, t, b.d.AddLast)
+}
+
+// TakeFirst 从队首取出元素，队列空的时候阻塞
+func (b *BlockingDeque[T]) TakeFirst(ctx context.Context) (T, error) {
+	return b.take(ctx, b.d.RemoveFirst)
+}
+
+// TakeLast 从队尾取出元素，队列空的时候阻塞
+func (b *BlockingDeque[T]) TakeLast(ctx context.Context) (T, error) {
+	return b.take(ctx, b.d.RemoveLast)
+}
+
+// OfferFirst 在队首放入元素，队列满的时候返回 queue.ErrOutOfCapacity
+func (b *BlockingDeque[T]) OfferFirst(t T) error {
+	return b.offer(t, b.d.AddFirst)
+}
+
+// OfferLast 在队尾放入元素，队列满的时候返回 queue.ErrOutOfCapacity
+func (b *BlockingDeque[T]) OfferLast(t T) error {
+	return b.offer(t, b.d.AddLast)
+}
+
+// PollFirst 从队首取出元素，队列空的时候返回 deque.ErrEmptyDeque
+func (b *BlockingDeque[T]) PollFirst() (T, error) {
+	return b.poll(b.d.RemoveFirst)
+}
+
+// PollLast 从队尾取出元素，队列空的时候返回 deque.ErrEmptyDeque
+func (b *BlockingDeque[T]) PollLast() (T, error) {
+	return b.poll(b.d.RemoveLast)
+}
+
+// PeekFirst 返回队首元素但不取出，队列空的时候返回 deque.ErrEmptyDeque
+func (b *BlockingDeque[T]) PeekFirst() (T, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.d.PeekFirst()
+}
+
+// PeekLast 返回队尾元素但不取出，队列空的时候返回 deque.ErrEmptyDeque
+func (b *BlockingDeque[T]) PeekLast() (T, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.d.PeekLast()
+}
+
+func (b *BlockingDeque[T]) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.d.Len()
+}
+
+// Cap 无界队列返回0，有界队列返回创建队列时设置的值
+func (b *BlockingDeque[T]) Cap() int {
+	return b.capacity
+}
+
+func (b *BlockingDeque[T]) isFull() bool {
+	return b.capacity > 0 && b.d.Len() >= b.capacity
+}
+
+func (b *BlockingDeque[T]) put(ctx context.Context, t T, add func(t T) error) error {
+	for {
+		select {
+		// 先检测 ctx 有没有过期
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		b.mutex.Lock()
+		if !b.isFull() {
+			err := add(t)
+			b.notEmpty.Broadcast()
+			return err
+		}
+		signal := b.notFull.SignalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+			// 有元素被取走了，进入下一个循环重新检查
+		}
+	}
+}
+
+func (b *BlockingDeque[T]) take(ctx context.Context, remove func() (T, error)) (T, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			var t T
+			return t, ctx.Err()
+		default:
+		}
+		b.mutex.Lock()
+		if b.d.Len() > 0 {
+			t, err := remove()
+			b.notFull.Broadcast()
+			return t, err
+		}
+		signal := b.notEmpty.SignalCh()
+		select {
+		case <-ctx.Done():
+			var t T
+			return t, ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+func (b *BlockingDeque[T]) offer(t T, add func(t T) error) error {
+	b.mutex.Lock()
+	if b.isFull() {
+		b.mutex.Unlock()
+		return queue.ErrOutOfCapacity
+	}
+	err := add(t)
+	b.notEmpty.Broadcast()
+	return err
+}
+
+func (b *BlockingDeque[T]) poll(remove func() (T, error)) (T, error) {
+	b.mutex.Lock()
+	if b.d.Len() == 0 {
+		b.mutex.Unlock()
+		var t T
+		return t, deque.ErrEmptyDeque
+	}
+	t, err := remove()
+	b.notFull.Broadcast()
+	return t, err
+}
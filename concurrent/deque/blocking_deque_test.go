@@ -0,0 +1,265 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deque
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/igevin/algokit/collection/deque"
+	"github.com/igevin/algokit/collection/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestBlockingDeque_OfferPoll(t *testing.T) {
+	t.Parallel()
+	q := NewBlockingDeque[int](3)
+	require.NoError(t, q.OfferLast(2))
+	require.NoError(t, q.OfferFirst(1))
+	require.NoError(t, q.OfferLast(3))
+	assert.Equal(t, queue.ErrOutOfCapacity, q.OfferLast(4))
+	assert.Equal(t, queue.ErrOutOfCapacity, q.OfferFirst(0))
+	assert.Equal(t, 3, q.Len())
+	assert.Equal(t, 3, q.Cap())
+
+	v, err := q.PeekFirst()
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, err = q.PeekLast()
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+
+	v, err = q.PollLast()
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+	v, err = q.PollFirst()
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, err = q.PollFirst()
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+	_, err = q.PollFirst()
+	assert.Equal(t, deque.ErrEmptyDeque, err)
+	_, err = q.PollLast()
+	assert.Equal(t, deque.ErrEmptyDeque, err)
+	_, err = q.PeekFirst()
+	assert.Equal(t, deque.ErrEmptyDeque, err)
+}
+
+func TestBlockingDeque_Unbounded(t *testing.T) {
+	t.Parallel()
+	q := NewBlockingDeque[int](-1)
+	assert.Equal(t, 0, q.Cap())
+	for i := 0; i < 100; i++ {
+		require.NoError(t, q.PutFirst(context.Background(), i))
+	}
+	for i := 0; i < 100; i++ {
+		v, err := q.TakeLast(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, i, v)
+	}
+}
+
+// TestBlockingDeque_LargeCapacity 很大的上限不会在创建的时候分配对应大小的缓冲区
+func TestBlockingDeque_LargeCapacity(t *testing.T) {
+	t.Parallel()
+	q := NewBlockingDeque[int](1 << 30)
+	assert.Equal(t, 1<<30, q.Cap())
+	assert.Less(t, q.d.Cap(), 1024)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, q.OfferLast(i))
+	}
+	assert.Equal(t, 100, q.Len())
+}
+
+func TestBlockingDeque_Put(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		q       func() *BlockingDeque[int]
+		timeout time.Duration
+		wantErr error
+	}{
+		{
+			name: "not full",
+			q: func() *BlockingDeque[int] {
+				return NewBlockingDeque[int](2)
+			},
+			timeout: time.Second,
+		},
+		{
+			name: "invalid context",
+			q: func() *BlockingDeque[int] {
+				return NewBlockingDeque[int](1)
+			},
+			timeout: -time.Second,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "full and timeout",
+			q: func() *BlockingDeque[int] {
+				q := NewBlockingDeque[int](1)
+				_ = q.OfferLast(1)
+				return q
+			},
+			timeout: time.Millisecond * 50,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.q()
+			assert.Equal(t, tc.wantErr, q.PutFirst(ctx, 10))
+			assert.Equal(t, tc.wantErr, q.PutLast(ctx, 10))
+		})
+	}
+
+	t.Run("put while take", func(t *testing.T) {
+		q := NewBlockingDeque[int](1)
+		require.NoError(t, q.OfferLast(1))
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			_, _ = q.PollFirst()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.PutFirst(ctx, 2))
+		v, err := q.PeekFirst()
+		require.NoError(t, err)
+		assert.Equal(t, 2, v)
+	})
+}
+
+func TestBlockingDeque_Take(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		q       func() *BlockingDeque[int]
+		timeout time.Duration
+		wantErr error
+	}{
+		{
+			name: "not empty",
+			q: func() *BlockingDeque[int] {
+				q := NewBlockingDeque[int](2)
+				_ = q.OfferLast(1)
+				_ = q.OfferLast(1)
+				return q
+			},
+			timeout: time.Second,
+		},
+		{
+			name: "invalid context",
+			q: func() *BlockingDeque[int] {
+				q := NewBlockingDeque[int](2)
+				_ = q.OfferLast(1)
+				_ = q.OfferLast(1)
+				return q
+			},
+			timeout: -time.Second,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "empty and timeout",
+			q: func() *BlockingDeque[int] {
+				return NewBlockingDeque[int](2)
+			},
+			timeout: time.Millisecond * 50,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.q()
+			_, err := q.TakeFirst(ctx)
+			assert.Equal(t, tc.wantErr, err)
+			_, err = q.TakeLast(ctx)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+
+	t.Run("take while put", func(t *testing.T) {
+		q := NewBlockingDeque[int](1)
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			_ = q.OfferFirst(123)
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		v, err := q.TakeLast(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 123, v)
+	})
+}
+
+// TestBlockingDeque_Concurrent 多个生产者和消费者在容量很小的队列上竞争，每个元素恰好被取出一次
+func TestBlockingDeque_Concurrent(t *testing.T) {
+	t.Parallel()
+	const producers, perProducer = 8, 500
+	q := NewBlockingDeque[int](4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var eg errgroup.Group
+	for p := 0; p < producers; p++ {
+		eg.Go(func() error {
+			for i := 0; i < perProducer; i++ {
+				v := p*perProducer + i
+				put := q.PutLast
+				if i%2 == 0 {
+					put = q.PutFirst
+				}
+				if err := put(ctx, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	var mu sync.Mutex
+	seen := make(map[int]int, producers*perProducer)
+	for c := 0; c < producers; c++ {
+		eg.Go(func() error {
+			for i := 0; i < perProducer; i++ {
+				take := q.TakeFirst
+				if i%2 == 0 {
+					take = q.TakeLast
+				}
+				v, err := take(ctx)
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				seen[v]++
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	require.NoError(t, eg.Wait())
+	assert.Len(t, seen, producers*perProducer)
+	for v, cnt := range seen {
+		assert.Equal(t, 1, cnt, "value %d", v)
+	}
+	assert.Equal(t, 0, q.Len())
+}
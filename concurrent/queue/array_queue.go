@@ -0,0 +1,123 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"sync/atomic"
+
+	"github.com/igevin/algokit/collection/queue"
+)
+
+// ConcurrentArrayQueue 有界无锁并发队列，支持多生产者多消费者
+// 采用 Dmitry Vyukov 的方案：每一个槽位带有一个序号，
+// 生产者和消费者通过比较序号和自己的位置来判断槽位是否可写、可读，
+// 只需要对 enqueuePos 或者 dequeuePos 做一次 CAS，也不需要为每个元素分配节点
+type ConcurrentArrayQueue[T any] struct {
+	_          cacheLinePad
+	enqueuePos atomic.Uint64
+	_          cacheLinePad
+	dequeuePos atomic.Uint64
+	_          cacheLinePad
+	mask       uint64
+	cells      []arrayQueueCell[T]
+}
+
+type arrayQueueCell[T any] struct {
+	// seq 等于位置 pos 时表示可以写入 pos，等于 pos+1 时表示 pos 的数据可以读取
+	seq atomic.Uint64
+	val T
+}
+
+// NewConcurrentArrayQueue 创建有界并发队列，capacity 会向上取整到 2 的幂，最小为 2
+func NewConcurrentArrayQueue[T any](capacity int) *ConcurrentArrayQueue[T] {
+	c := 2
+	for c < capacity {
+		c <<= 1
+	}
+	cells := make([]arrayQueueCell[T], c)
+	for i := range cells {
+		cells[i].seq.Store(uint64(i))
+	}
+	return &ConcurrentArrayQueue[T]{
+		mask:  uint64(c - 1),
+		cells: cells,
+	}
+}
+
+// TryEnqueue 放入元素，队列满了的时候立刻返回 queue.ErrOutOfCapacity
+func (c *ConcurrentArrayQueue[T]) TryEnqueue(t T) error {
+	pos := c.enqueuePos.Load()
+	for {
+		cell := &c.cells[pos&c.mask]
+		dif := int64(cell.seq.Load() - pos)
+		switch {
+		case dif == 0:
+			if c.enqueuePos.CompareAndSwap(pos, pos+1) {
+				cell.val = t
+				// 发布数据，消费者看到新的序号时一定能看到 val
+				cell.seq.Store(pos + 1)
+				return nil
+			}
+			pos = c.enqueuePos.Load()
+		case dif < 0:
+			// 槽位上一轮的数据还没有被取走，说明队列满了
+			return queue.ErrOutOfCapacity
+		default:
+			// 别的生产者抢先了，重新读取位置
+			pos = c.enqueuePos.Load()
+		}
+	}
+}
+
+// TryDequeue 取出元素，队列为空的时候立刻返回 queue.ErrEmptyQueue
+func (c *ConcurrentArrayQueue[T]) TryDequeue() (T, error) {
+	pos := c.dequeuePos.Load()
+	for {
+		cell := &c.cells[pos&c.mask]
+		dif := int64(cell.seq.Load() - (pos + 1))
+		switch {
+		case dif == 0:
+			if c.dequeuePos.CompareAndSwap(pos, pos+1) {
+				t := cell.val
+				var zero T
+				cell.val = zero
+				// 把槽位交给下一轮的生产者
+				cell.seq.Store(pos + c.mask + 1)
+				return t, nil
+			}
+			pos = c.dequeuePos.Load()
+		case dif < 0:
+			var zero T
+			return zero, queue.ErrEmptyQueue
+		default:
+			pos = c.dequeuePos.Load()
+		}
+	}
+}
+
+// Len 返回元素数量的近似值，并发修改时只能作为参考
+func (c *ConcurrentArrayQueue[T]) Len() int {
+	dequeuePos := c.dequeuePos.Load()
+	enqueuePos := c.enqueuePos.Load()
+	if enqueuePos <= dequeuePos {
+		return 0
+	}
+	return min(int(enqueuePos-dequeuePos), c.Cap())
+}
+
+// Cap 返回向上取整之后的实际容量
+func (c *ConcurrentArrayQueue[T]) Cap() int {
+	return int(c.mask + 1)
+}
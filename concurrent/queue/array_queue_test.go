@@ -0,0 +1,180 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConcurrentArrayQueue(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		capacity int
+		wantCap  int
+	}{
+		{name: "negative", capacity: -1, wantCap: 2},
+		{name: "power of two", capacity: 8, wantCap: 8},
+		{name: "round up", capacity: 9, wantCap: 16},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentArrayQueue[int](tc.capacity)
+			assert.Equal(t, tc.wantCap, q.Cap())
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
+
+func TestConcurrentArrayQueue_TryEnqueueDequeue(t *testing.T) {
+	t.Parallel()
+	q := NewConcurrentArrayQueue[int](4)
+	_, err := q.TryDequeue()
+	assert.Equal(t, errEmptyQueue, err)
+	// 多轮写满再读空，验证槽位序号在回绕之后依然正确
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 4; i++ {
+			require.NoError(t, q.TryEnqueue(round*10+i))
+		}
+		assert.Equal(t, errOutOfCapacity, q.TryEnqueue(100))
+		assert.Equal(t, 4, q.Len())
+		for i := 0; i < 4; i++ {
+			v, err := q.TryDequeue()
+			require.NoError(t, err)
+			assert.Equal(t, round*10+i, v)
+		}
+		_, err = q.TryDequeue()
+		assert.Equal(t, errEmptyQueue, err)
+	}
+}
+
+// TestConcurrentArrayQueue_Concurrent 多生产者多消费者，每个元素恰好被取出一次，
+// 并且同一个生产者的元素按照放入的顺序被取出
+func TestConcurrentArrayQueue_Concurrent(t *testing.T) {
+	t.Parallel()
+	const producers, perProducer = 4, 2000
+	q := NewConcurrentArrayQueue[[2]int](64)
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				for q.TryEnqueue([2]int{p, i}) != nil {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	var total atomic.Int64
+	results := make([][][2]int, producers)
+	for c := 0; c < producers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for total.Load() < producers*perProducer {
+				v, err := q.TryDequeue()
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+				total.Add(1)
+				results[c] = append(results[c], v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make([][]bool, producers)
+	for p := range seen {
+		seen[p] = make([]bool, perProducer)
+	}
+	for _, res := range results {
+		last := make([]int, producers)
+		for p := range last {
+			last[p] = -1
+		}
+		for _, v := range res {
+			require.False(t, seen[v[0]][v[1]], "duplicated %v", v)
+			seen[v[0]][v[1]] = true
+			// 单个消费者看到的同一生产者的元素是递增的
+			require.Greater(t, v[1], last[v[0]])
+			last[v[0]] = v[1]
+		}
+	}
+	for p := range seen {
+		for i := range seen[p] {
+			require.True(t, seen[p][i], "lost %d-%d", p, i)
+		}
+	}
+}
+
+func BenchmarkBoundedQueue(b *testing.B) {
+	const capacity = 1024
+	b.Run("ConcurrentArrayQueue", func(b *testing.B) {
+		q := NewConcurrentArrayQueue[int](capacity)
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				if i%2 == 0 {
+					_ = q.TryEnqueue(i)
+				} else {
+					_, _ = q.TryDequeue()
+				}
+				i++
+			}
+		})
+	})
+	b.Run("ConcurrentLinkedQueue", func(b *testing.B) {
+		q := NewConcurrentLinkedQueue[int]()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				if i%2 == 0 {
+					_ = q.Enqueue(i)
+				} else {
+					_, _ = q.Dequeue()
+				}
+				i++
+			}
+		})
+	})
+	b.Run("channel", func(b *testing.B) {
+		ch := make(chan int, capacity)
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				if i%2 == 0 {
+					select {
+					case ch <- i:
+					default:
+					}
+				} else {
+					select {
+					case <-ch:
+					default:
+					}
+				}
+				i++
+			}
+		})
+	})
+}
@@ -0,0 +1,270 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/igevin/algokit/collection/deque"
+	"github.com/igevin/algokit/collection/queue"
+	"github.com/igevin/algokit/internal/syncx"
+)
+
+// ArrayBlockingQueue 基于环形缓冲区的阻塞队列
+// 元素连续存放，没有额外的节点分配，适合容量不大、吞吐量高的场景
+type ArrayBlockingQueue[T any] struct {
+	*blockingQueue[T]
+}
+
+// NewArrayBlockingQueue 创建阻塞队列，capacity <= 0 时为无界队列
+// 有界队列会预先分配 capacity 大小的缓冲区，之后不会扩缩容；无界队列的缓冲区按需扩缩容
+func NewArrayBlockingQueue[T any](capacity int) *ArrayBlockingQueue[T] {
+	store := deque.NewArrayDequeue[T](0)
+	if capacity > 0 {
+		store = deque.NewFixedArrayDequeue[T](capacity)
+	}
+	return &ArrayBlockingQueue[T]{
+		blockingQueue: newBlockingQueue[T](store, capacity),
+	}
+}
+
+// LinkedBlockingQueue 基于链表的阻塞队列
+// 节点按需分配，适合无界或者容量很大、但是平时元素不多的场景
+type LinkedBlockingQueue[T any] struct {
+	*blockingQueue[T]
+}
+
+// NewLinkedBlockingQueue 创建阻塞队列，capacity <= 0 时为无界队列
+func NewLinkedBlockingQueue[T any](capacity int) *LinkedBlockingQueue[T] {
+	return &LinkedBlockingQueue[T]{
+		blockingQueue: newBlockingQueue[T](deque.NewLinkedDeque[T](), capacity),
+	}
+}
+
+type blockingStore[T any] interface {
+	AddLast(t T) error
+	RemoveFirst() (T, error)
+	Len() int
+}
+
+// blockingQueue 是 ArrayBlockingQueue 和 LinkedBlockingQueue 共用的实现，两者只是存储结构不同
+// Enqueue 在队列满的时候阻塞，Dequeue 在队列空的时候阻塞，直到条件满足、ctx 结束或者队列被关闭
+// 关闭之后不能再放入元素，但是已有的元素依旧可以取出，取完之后返回 ErrQueueClosed
+type blockingQueue[T any] struct {
+	store    blockingStore[T]
+	capacity int
+	closed   bool
+	mutex    *sync.Mutex
+	// notEmpty 在放入元素或者关闭之后广播，唤醒 Dequeue
+	notEmpty *syncx.Cond
+	// notFull 在取出元素或者关闭之后广播，唤醒 Enqueue
+	notFull *syncx.Cond
+}
+
+func newBlockingQueue[T any](store blockingStore[T], capacity int) *blockingQueue[T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	m := &sync.Mutex{}
+	return &blockingQueue[T]{
+		store:    store,
+		capacity: capacity,
+		mutex:    m,
+		notEmpty: syncx.NewCond(m),
+		notFull:  syncx.NewCond(m),
+	}
+}
+
+// Enqueue 放入元素，队列满的时候阻塞
+// 队列关闭之后返回 ErrQueueClosed
+func (b *blockingQueue[T]) Enqueue(ctx context.Context, t T) error {
+	for {
+		select {
+		// 先检测 ctx 有没有过期
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		b.mutex.Lock()
+		if b.closed {
+			b.mutex.Unlock()
+			return ErrQueueClosed
+		}
+		if !b.isFull() {
+			err := b.store.AddLast(t)
+			b.notEmpty.Broadcast()
+			return err
+		}
+		signal := b.notFull.SignalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+			// 有元素被取走了或者队列被关闭了，进入下一个循环重新检查
+		}
+	}
+}
+
+// Dequeue 取出元素，队列空的时候阻塞
+// 队列关闭并且已经没有元素的时候返回 ErrQueueClosed
+func (b *blockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			var t T
+			return t, ctx.Err()
+		default:
+		}
+		b.mutex.Lock()
+		if b.store.Len() > 0 {
+			t, err := b.store.RemoveFirst()
+			b.notFull.Broadcast()
+			return t, err
+		}
+		if b.closed {
+			b.mutex.Unlock()
+			var t T
+			return t, ErrQueueClosed
+		}
+		signal := b.notEmpty.SignalCh()
+		select {
+		case <-ctx.Done():
+			var t T
+			return t, ctx.Err()
+		case <-signal:
+		}
+	}
+}
+
+// Offer 放入元素，队列满的时候返回 queue.ErrOutOfCapacity，不会阻塞
+func (b *blockingQueue[T]) Offer(t T) error {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	if b.isFull() {
+		b.mutex.Unlock()
+		return queue.ErrOutOfCapacity
+	}
+	err := b.store.AddLast(t)
+	b.notEmpty.Broadcast()
+	return err
+}
+
+// Poll 取出元素，队列空的时候返回 queue.ErrEmptyQueue，不会阻塞
+func (b *blockingQueue[T]) Poll() (T, error) {
+	b.mutex.Lock()
+	if b.store.Len() == 0 {
+		closed := b.closed
+		b.mutex.Unlock()
+		var t T
+		if closed {
+			return t, ErrQueueClosed
+		}
+		return t, queue.ErrEmptyQueue
+	}
+	t, err := b.store.RemoveFirst()
+	b.notFull.Broadcast()
+	return t, err
+}
+
+// OfferTimeout 放入元素，队列满的时候最多等待 timeout，超时之后返回 queue.ErrOutOfCapacity
+func (b *blockingQueue[T]) OfferTimeout(t T, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := b.Enqueue(ctx, t)
+	if errors.Is(err, context.DeadlineExceeded) {
+		// 超时的时候可能恰好有空位，再尝试一次
+		return b.Offer(t)
+	}
+	return err
+}
+
+// PollTimeout 取出元素，队列空的时候最多等待 timeout，超时之后返回 queue.ErrEmptyQueue
+func (b *blockingQueue[T]) PollTimeout(timeout time.Duration) (T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	t, err := b.Dequeue(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return b.Poll()
+	}
+	return t, err
+}
+
+// DrainTo 一次性取出最多 maxElements 个元素，追加到 dst 之后返回
+// maxElements <= 0 时取出所有元素；队列为空的时候不会阻塞
+func (b *blockingQueue[T]) DrainTo(dst []T, maxElements int) []T {
+	b.mutex.Lock()
+	n := b.store.Len()
+	if maxElements > 0 {
+		n = min(n, maxElements)
+	}
+	if n == 0 {
+		b.mutex.Unlock()
+		return dst
+	}
+	for i := 0; i < n; i++ {
+		// 加锁之后已经确认了元素数量，不会出错
+		t, _ := b.store.RemoveFirst()
+		dst = append(dst, t)
+	}
+	b.notFull.Broadcast()
+	return dst
+}
+
+// Close 关闭队列，并且唤醒所有等待中的 Enqueue 和 Dequeue
+// 重复关闭没有任何效果
+func (b *blockingQueue[T]) Close() error {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.notEmpty.Broadcast()
+	b.mutex.Lock()
+	b.notFull.Broadcast()
+	return nil
+}
+
+// RemainingCapacity 返回还能放入多少个元素而不阻塞，无界队列返回 math.MaxInt
+func (b *blockingQueue[T]) RemainingCapacity() int {
+	if b.capacity == 0 {
+		return math.MaxInt
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.capacity - b.store.Len()
+}
+
+func (b *blockingQueue[T]) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.store.Len()
+}
+
+// Cap 无界队列返回0，有界队列返回创建队列时设置的值
+func (b *blockingQueue[T]) Cap() int {
+	return b.capacity
+}
+
+func (b *blockingQueue[T]) isFull() bool {
+	return b.capacity > 0 && b.store.Len() >= b.capacity
+}
@@ -0,0 +1,373 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/igevin/algokit/collection/deque"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+// blockingQueues 两种阻塞队列的行为完全一致，所有测试对两者都跑一遍
+func blockingQueues(capacity int, vals ...int) map[string]*blockingQueue[int] {
+	res := map[string]*blockingQueue[int]{
+		"array":  NewArrayBlockingQueue[int](capacity).blockingQueue,
+		"linked": NewLinkedBlockingQueue[int](capacity).blockingQueue,
+	}
+	for _, q := range res {
+		for _, v := range vals {
+			_ = q.Offer(v)
+		}
+	}
+	return res
+}
+
+func TestBlockingQueue_OfferPoll(t *testing.T) {
+	t.Parallel()
+	for name, q := range blockingQueues(2) {
+		t.Run(name, func(t *testing.T) {
+			_, err := q.Poll()
+			assert.Equal(t, errEmptyQueue, err)
+			assert.Equal(t, 2, q.RemainingCapacity())
+			require.NoError(t, q.Offer(1))
+			require.NoError(t, q.Offer(2))
+			assert.Equal(t, errOutOfCapacity, q.Offer(3))
+			assert.Equal(t, 2, q.Len())
+			assert.Equal(t, 2, q.Cap())
+			assert.Equal(t, 0, q.RemainingCapacity())
+			for _, want := range []int{1, 2} {
+				val, err := q.Poll()
+				require.NoError(t, err)
+				assert.Equal(t, want, val)
+			}
+		})
+	}
+}
+
+func TestBlockingQueue_Unbounded(t *testing.T) {
+	t.Parallel()
+	for name, q := range blockingQueues(-1) {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				require.NoError(t, q.Enqueue(context.Background(), i))
+			}
+			assert.Equal(t, 100, q.Len())
+			assert.Equal(t, 0, q.Cap())
+			assert.Equal(t, math.MaxInt, q.RemainingCapacity())
+			for i := 0; i < 100; i++ {
+				val, err := q.Dequeue(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, i, val)
+			}
+		})
+	}
+}
+
+// TestArrayBlockingQueue_FixedBuffer 有界队列的缓冲区大小固定为 capacity，取空再填满都不会重新分配
+func TestArrayBlockingQueue_FixedBuffer(t *testing.T) {
+	t.Parallel()
+	const capacity = 3000
+	q := NewArrayBlockingQueue[int](capacity)
+	store := q.store.(*deque.ArrayDequeue[int])
+	for round := 0; round < 2; round++ {
+		for i := 0; i < capacity; i++ {
+			require.NoError(t, q.Offer(i))
+		}
+		assert.Equal(t, errOutOfCapacity, q.Offer(capacity))
+		assert.Equal(t, capacity, store.Cap())
+		assert.Len(t, q.DrainTo(nil, -1), capacity)
+		assert.Equal(t, capacity, store.Cap())
+	}
+}
+
+func TestBlockingQueue_Enqueue(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		vals    []int
+		timeout time.Duration
+		// before 在 Enqueue 之前执行，一般用于在后台唤醒 Enqueue
+		before func(q *blockingQueue[int])
+
+		wantErr  error
+		wantData []int
+	}{
+		{
+			name:     "not full",
+			vals:     []int{1},
+			timeout:  time.Second,
+			wantData: []int{1, 10},
+		},
+		{
+			name:     "full timeout",
+			vals:     []int{1, 2},
+			timeout:  10 * time.Millisecond,
+			wantErr:  context.DeadlineExceeded,
+			wantData: []int{1, 2},
+		},
+		{
+			name:    "wake up by dequeue",
+			vals:    []int{1, 2},
+			timeout: time.Second,
+			before: func(q *blockingQueue[int]) {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					_, _ = q.Poll()
+				}()
+			},
+			wantData: []int{2, 10},
+		},
+		{
+			name:    "wake up by close",
+			vals:    []int{1, 2},
+			timeout: time.Second,
+			before: func(q *blockingQueue[int]) {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					_ = q.Close()
+				}()
+			},
+			wantErr:  ErrQueueClosed,
+			wantData: []int{1, 2},
+		},
+	}
+	for _, tc := range testCases {
+		for name, q := range blockingQueues(2, tc.vals...) {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				if tc.before != nil {
+					tc.before(q)
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+				defer cancel()
+				err := q.Enqueue(ctx, 10)
+				assert.Equal(t, tc.wantErr, err)
+				assert.Equal(t, tc.wantData, q.DrainTo(nil, 0))
+			})
+		}
+	}
+}
+
+func TestBlockingQueue_Dequeue(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		vals    []int
+		timeout time.Duration
+		before  func(q *blockingQueue[int])
+
+		wantVal int
+		wantErr error
+	}{
+		{
+			name:    "not empty",
+			vals:    []int{1, 2},
+			timeout: time.Second,
+			wantVal: 1,
+		},
+		{
+			name:    "empty timeout",
+			timeout: 10 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "wake up by enqueue",
+			timeout: time.Second,
+			before: func(q *blockingQueue[int]) {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					_ = q.Offer(3)
+				}()
+			},
+			wantVal: 3,
+		},
+		{
+			name:    "wake up by close",
+			timeout: time.Second,
+			before: func(q *blockingQueue[int]) {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					_ = q.Close()
+				}()
+			},
+			wantErr: ErrQueueClosed,
+		},
+		{
+			name:    "closed but not empty",
+			vals:    []int{1},
+			timeout: time.Second,
+			before: func(q *blockingQueue[int]) {
+				_ = q.Close()
+			},
+			wantVal: 1,
+		},
+	}
+	for _, tc := range testCases {
+		for name, q := range blockingQueues(2, tc.vals...) {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				if tc.before != nil {
+					tc.before(q)
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+				defer cancel()
+				val, err := q.Dequeue(ctx)
+				assert.Equal(t, tc.wantErr, err)
+				assert.Equal(t, tc.wantVal, val)
+			})
+		}
+	}
+}
+
+func TestBlockingQueue_Timeout(t *testing.T) {
+	t.Parallel()
+	for name, q := range blockingQueues(1) {
+		t.Run(name, func(t *testing.T) {
+			_, err := q.PollTimeout(10 * time.Millisecond)
+			assert.Equal(t, errEmptyQueue, err)
+			require.NoError(t, q.OfferTimeout(1, 10*time.Millisecond))
+			assert.Equal(t, errOutOfCapacity, q.OfferTimeout(2, 10*time.Millisecond))
+
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				_, _ = q.Poll()
+			}()
+			require.NoError(t, q.OfferTimeout(3, time.Second))
+			val, err := q.PollTimeout(time.Second)
+			require.NoError(t, err)
+			assert.Equal(t, 3, val)
+		})
+	}
+}
+
+func TestBlockingQueue_DrainTo(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name        string
+		vals        []int
+		dst         []int
+		maxElements int
+
+		wantRes []int
+		wantLen int
+	}{
+		{
+			name:    "empty",
+			dst:     []int{0},
+			wantRes: []int{0},
+		},
+		{
+			name:    "all",
+			vals:    []int{1, 2, 3},
+			dst:     []int{0},
+			wantRes: []int{0, 1, 2, 3},
+		},
+		{
+			name:        "limited",
+			vals:        []int{1, 2, 3},
+			maxElements: 2,
+			wantRes:     []int{1, 2},
+			wantLen:     1,
+		},
+		{
+			name:        "more than len",
+			vals:        []int{1, 2, 3},
+			maxElements: 5,
+			wantRes:     []int{1, 2, 3},
+		},
+	}
+	for _, tc := range testCases {
+		for name, q := range blockingQueues(3, tc.vals...) {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				res := q.DrainTo(tc.dst, tc.maxElements)
+				assert.Equal(t, tc.wantRes, res)
+				assert.Equal(t, tc.wantLen, q.Len())
+			})
+		}
+	}
+}
+
+func TestBlockingQueue_Close(t *testing.T) {
+	t.Parallel()
+	for name, q := range blockingQueues(1, 1) {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, q.Close())
+			// 重复关闭
+			require.NoError(t, q.Close())
+			assert.Equal(t, ErrQueueClosed, q.Offer(2))
+			assert.Equal(t, ErrQueueClosed, q.Enqueue(context.Background(), 2))
+			val, err := q.Poll()
+			require.NoError(t, err)
+			assert.Equal(t, 1, val)
+			_, err = q.Poll()
+			assert.Equal(t, ErrQueueClosed, err)
+			_, err = q.PollTimeout(time.Second)
+			assert.Equal(t, ErrQueueClosed, err)
+		})
+	}
+}
+
+// TestBlockingQueue_Concurrent 多个生产者和消费者，关闭之后所有等待者都会退出，
+// 并且每个元素恰好被取出一次
+func TestBlockingQueue_Concurrent(t *testing.T) {
+	t.Parallel()
+	const producers, perProducer = 4, 500
+	for name, q := range blockingQueues(8) {
+		t.Run(name, func(t *testing.T) {
+			var mutex sync.Mutex
+			seen := make(map[int]int, producers*perProducer)
+			var consumers errgroup.Group
+			for i := 0; i < producers; i++ {
+				consumers.Go(func() error {
+					for {
+						val, err := q.Dequeue(context.Background())
+						if err == ErrQueueClosed {
+							return nil
+						}
+						if err != nil {
+							return err
+						}
+						mutex.Lock()
+						seen[val]++
+						mutex.Unlock()
+					}
+				})
+			}
+			var eg errgroup.Group
+			for p := 0; p < producers; p++ {
+				eg.Go(func() error {
+					for i := 0; i < perProducer; i++ {
+						if err := q.Enqueue(context.Background(), p*perProducer+i); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			}
+			require.NoError(t, eg.Wait())
+			require.NoError(t, q.Close())
+			require.NoError(t, consumers.Wait())
+			assert.Len(t, seen, producers*perProducer)
+			for val, cnt := range seen {
+				assert.Equal(t, 1, cnt, "value %d", val)
+			}
+		})
+	}
+}
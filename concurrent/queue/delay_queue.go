@@ -15,73 +15,107 @@
 package queue
 
 import (
+	"container/heap"
 	"context"
-	"errors"
-	"fmt"
 	"sync"
 	"time"
 
 	"github.com/igevin/algokit/collection/queue"
+	"github.com/igevin/algokit/concurrent/clock"
+	"github.com/igevin/algokit/internal/syncx"
 )
 
+// DelayQueue 延时队列，元素到期之后才能出队
+// 元素的到期时间在入队的时候根据时钟的当前时间加上 Delay() 计算出来并记录下来，
+// 之后可以通过 DelayHandle 取消或者修改
+// capacity <= 0 时为无界队列
 type DelayQueue[T Delayable] struct {
-	q             queue.PriorityQueue[T]
-	mutex         *sync.Mutex
-	dequeueSignal *cond
-	enqueueSignal *cond
+	// h 按照到期时间排序的小顶堆，每个元素记录了自己在堆中的下标，所以可以在 O(log n) 内删除和调整
+	h        delayHeap[T]
+	capacity int
+	closed   bool
+	clock    clock.Clock
+	mutex    *sync.Mutex
+	// dequeueSignal 在元素出队或者被取消之后广播，唤醒 Enqueue
+	dequeueSignal *syncx.Cond
+	// enqueueSignal 在队头可能发生变化的时候广播，唤醒 Dequeue 重新计算等待时间
+	enqueueSignal *syncx.Cond
 }
 
-func NewDelayQueue[T Delayable](c int) *DelayQueue[T] {
+// DelayQueueOption 创建 DelayQueue 时的配置项，和元素类型无关
+type DelayQueueOption func(c *delayQueueConfig)
+
+type delayQueueConfig struct {
+	clock clock.Clock
+}
+
+// NewDelayQueue 创建延时队列，c <= 0 时为无界队列
+func NewDelayQueue[T Delayable](c int, opts ...DelayQueueOption) *DelayQueue[T] {
+	if c < 0 {
+		c = 0
+	}
+	cfg := delayQueueConfig{
+		clock: clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	m := &sync.Mutex{}
-	res := &DelayQueue[T]{
-		q: *queue.NewPriorityQueue[T](c, func(src T, dst T) int {
-			srcDelay := src.Delay()
-			dstDelay := dst.Delay()
-			if srcDelay > dstDelay {
-				return 1
-			}
-			if srcDelay == dstDelay {
-				return 0
-			}
-			return -1
-		}),
+	return &DelayQueue[T]{
+		capacity:      c,
+		clock:         cfg.clock,
 		mutex:         m,
-		dequeueSignal: newCond(m),
-		enqueueSignal: newCond(m),
+		dequeueSignal: syncx.NewCond(m),
+		enqueueSignal: syncx.NewCond(m),
 	}
-	return res
 }
 
-func (d *DelayQueue[T]) Enqueue(ctx context.Context, t T) error {
+// WithClock 设置时钟，默认为真实时钟
+// 测试的时候可以传入 clock.Fake，在虚拟时间里验证延时相关的逻辑
+func WithClock(c clock.Clock) DelayQueueOption {
+	return func(cfg *delayQueueConfig) {
+		cfg.clock = c
+	}
+}
+
+// Enqueue 放入元素，队列满的时候阻塞
+// 返回的 DelayHandle 可以用来取消元素或者修改它的到期时间；队列关闭之后返回 ErrQueueClosed
+func (d *DelayQueue[T]) Enqueue(ctx context.Context, t T) (*DelayHandle[T], error) {
 	for {
 		select {
 		// 先检测 ctx 有没有过期
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 		d.mutex.Lock()
-		err := d.q.Enqueue(t)
-		switch {
-		case err == nil:
-			d.enqueueSignal.broadcast()
-			return nil
-		case errors.Is(err, queue.ErrOutOfCapacity):
-			signal := d.dequeueSignal.signalCh()
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-signal:
-			}
-		default:
+		if d.closed {
 			d.mutex.Unlock()
-			return fmt.Errorf("algokit: 延时队列入队的时候遇到未知错误 %w，请上报", err)
+			return nil, ErrQueueClosed
+		}
+		if d.capacity <= 0 || d.h.Len() < d.capacity {
+			h := &DelayHandle[T]{
+				q:        d,
+				val:      t,
+				deadline: d.clock.Now().Add(t.Delay()),
+			}
+			heap.Push(&d.h, h)
+			d.enqueueSignal.Broadcast()
+			return h, nil
+		}
+		signal := d.dequeueSignal.SignalCh()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-signal:
 		}
 	}
 }
 
+// Dequeue 取出到期的元素，没有到期的元素时阻塞
+// 队列关闭之后返回 ErrQueueClosed
 func (d *DelayQueue[T]) Dequeue(ctx context.Context) (T, error) {
-	var timer *time.Timer
+	var timer clock.Timer
 	defer func() {
 		if timer != nil {
 			timer.Stop()
@@ -96,87 +130,159 @@ func (d *DelayQueue[T]) Dequeue(ctx context.Context) (T, error) {
 		default:
 		}
 		d.mutex.Lock()
-		val, err := d.q.Peek()
-		switch {
-		case err == nil:
-			delay := val.Delay()
-			if delay <= 0 {
-				val, err = d.q.Dequeue()
-				d.dequeueSignal.broadcast()
-				// 理论上来说这里 err 不可能不为 nil
-				return val, err
-			}
-			signal := d.enqueueSignal.signalCh()
-			if timer == nil {
-				timer = time.NewTimer(delay)
-			} else {
-				timer.Reset(delay)
-			}
-			select {
-			case <-ctx.Done():
-				var t T
-				return t, ctx.Err()
-			case <-timer.C:
-				// 到了时间
-				d.mutex.Lock()
-				// 原队头可能已经被其他协程先出队，故再次检查队头
-				val, err := d.q.Peek()
-				if err != nil || val.Delay() > 0 {
-					d.mutex.Unlock()
-					continue
-				}
-				// 验证元素过期后将其出队
-				val, err = d.q.Dequeue()
-				d.dequeueSignal.broadcast()
-				return val, err
-			case <-signal:
-				// 进入下一个循环。这里可能是有新的元素入队，也可能是到期了
-			}
-		case errors.Is(err, queue.ErrEmptyQueue):
-			signal := d.enqueueSignal.signalCh()
+		if d.closed {
+			d.mutex.Unlock()
+			var t T
+			return t, ErrQueueClosed
+		}
+		if d.h.Len() == 0 {
+			signal := d.enqueueSignal.SignalCh()
 			select {
 			case <-ctx.Done():
 				var t T
 				return t, ctx.Err()
 			case <-signal:
 			}
-		default:
-			d.mutex.Unlock()
+			continue
+		}
+		head := d.h[0]
+		delay := head.deadline.Sub(d.clock.Now())
+		if delay <= 0 {
+			heap.Remove(&d.h, head.index)
+			d.dequeueSignal.Broadcast()
+			return head.val, nil
+		}
+		signal := d.enqueueSignal.SignalCh()
+		if timer == nil {
+			timer = d.clock.NewTimer(delay)
+		} else {
+			timer.Reset(delay)
+		}
+		select {
+		case <-ctx.Done():
 			var t T
-			return t, fmt.Errorf("algokit: 延时队列出队的时候遇到未知错误 %w，请上报", err)
+			return t, ctx.Err()
+		case <-timer.C():
+			// 到了时间，但是队头可能已经被其他协程先出队，或者被取消、修改，
+			// 所以进入下一个循环重新检查
+		case <-signal:
+			// 队头可能发生了变化，进入下一个循环重新计算等待时间
 		}
 	}
 }
 
-type cond struct {
-	signal chan struct{}
-	l      sync.Locker
+// Peek 返回最早到期的元素，不管它是否已经到期，也不会把它取出来
+// 队列为空的时候返回 queue.ErrEmptyQueue
+func (d *DelayQueue[T]) Peek() (T, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.h.Len() == 0 {
+		var t T
+		return t, queue.ErrEmptyQueue
+	}
+	return d.h[0].val, nil
+}
+
+func (d *DelayQueue[T]) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.h.Len()
+}
+
+// Close 关闭队列，唤醒所有等待中的 Enqueue 和 Dequeue，它们都会返回 ErrQueueClosed
+// 队列中剩余的元素不会再出队；重复关闭没有任何效果
+func (d *DelayQueue[T]) Close() error {
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.enqueueSignal.Broadcast()
+	d.mutex.Lock()
+	d.dequeueSignal.Broadcast()
+	return nil
+}
+
+// DelayHandle 代表延时队列中的一个元素，用于取消元素或者修改它的到期时间
+type DelayHandle[T Delayable] struct {
+	q        *DelayQueue[T]
+	val      T
+	deadline time.Time
+	// index 在堆中的下标，-1 表示已经不在队列中了
+	index int
 }
 
-func newCond(l sync.Locker) *cond {
-	return &cond{
-		signal: make(chan struct{}),
-		l:      l,
+// Value 返回入队的元素
+func (h *DelayHandle[T]) Value() T {
+	return h.val
+}
+
+// Cancel 把元素从队列中移除
+// 如果元素已经出队或者已经被取消，那么返回 false
+func (h *DelayHandle[T]) Cancel() bool {
+	d := h.q
+	d.mutex.Lock()
+	if h.index < 0 {
+		d.mutex.Unlock()
+		return false
+	}
+	wasHead := h.index == 0
+	heap.Remove(&d.h, h.index)
+	if wasHead {
+		d.enqueueSignal.Broadcast()
+		d.mutex.Lock()
 	}
+	d.dequeueSignal.Broadcast()
+	return true
+}
+
+// Reschedule 把元素的到期时间修改为从现在开始 newDelay 之后
+// 如果元素已经出队或者已经被取消，那么返回 false
+func (h *DelayHandle[T]) Reschedule(newDelay time.Duration) bool {
+	d := h.q
+	d.mutex.Lock()
+	if h.index < 0 {
+		d.mutex.Unlock()
+		return false
+	}
+	h.deadline = d.clock.Now().Add(newDelay)
+	heap.Fix(&d.h, h.index)
+	// 无论提前还是推迟，队头的等待时间都可能变化
+	d.enqueueSignal.Broadcast()
+	return true
+}
+
+// delayHeap 按照到期时间排序的小顶堆，实现了 heap.Interface，
+// 交换的时候会维护每个 DelayHandle 的下标，所以可以用 heap.Remove 和 heap.Fix 删除和调整任意元素
+type delayHeap[T Delayable] []*DelayHandle[T]
+
+func (h delayHeap[T]) Len() int {
+	return len(h)
+}
+
+func (h delayHeap[T]) Less(i, j int) bool {
+	return h[i].deadline.Before(h[j].deadline)
+}
+
+func (h delayHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *delayHeap[T]) Push(x any) {
+	e := x.(*DelayHandle[T])
+	e.index = len(*h)
+	*h = append(*h, e)
 }
 
-// broadcast 唤醒等待者
-// 如果没有人等待，那么什么也不会发生
-// 必须加锁之后才能调用这个方法
-// 广播之后锁会被释放，这也是为了确保用户必然是在锁范围内调用的
-func (c *cond) broadcast() {
-	signal := make(chan struct{})
-	old := c.signal
-	c.signal = signal
-	c.l.Unlock()
-	close(old)
-}
-
-// signalCh 返回一个 channel，用于监听广播信号
-// 必须在锁范围内使用
-// 调用后，锁会被释放，这也是为了确保用户必然是在锁范围内调用的
-func (c *cond) signalCh() <-chan struct{} {
-	res := c.signal
-	c.l.Unlock()
-	return res
+func (h *delayHeap[T]) Pop() any {
+	old := *h
+	n := len(old) - 1
+	e := old[n]
+	old[n] = nil
+	*h = old[:n]
+	e.index = -1
+	return e
 }
@@ -20,159 +20,146 @@ import (
 	"testing"
 	"time"
 
+	"github.com/igevin/algokit/concurrent/clock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 )
 
+// 除了 Example 以外，测试都使用手动推进的时钟，不需要真的等待元素到期
+
 func TestDelayQueue_Dequeue(t *testing.T) {
 	t.Parallel()
-	now := time.Now()
 	testCases := []struct {
-		name    string
-		q       *DelayQueue[delayElem]
+		name  string
+		elems []delayElem
+		// advance 在 Dequeue 开始等待之后推进的时间
+		advance time.Duration
 		timeout time.Duration
 		wantVal int
 		wantErr error
 	}{
 		{
-			name: "dequeued",
-			q: newDelayQueue(t, delayElem{
-				deadline: now.Add(time.Millisecond * 10),
-				val:      11,
-			}),
+			name:    "dequeued",
+			elems:   []delayElem{{delay: time.Millisecond * 10, val: 11}},
+			advance: time.Millisecond * 10,
 			timeout: time.Second,
 			wantVal: 11,
 		},
 		{
 			// 元素本身就已经过期了
-			name: "already deadline",
-			q: newDelayQueue(t, delayElem{
-				deadline: now.Add(-time.Millisecond * 10),
-				val:      11,
-			}),
+			name:    "already deadline",
+			elems:   []delayElem{{delay: -time.Millisecond * 10, val: 11}},
 			timeout: time.Second,
 			wantVal: 11,
 		},
 		{
 			// 已经超时了的 context 设置
-			name: "invalid context",
-			q: newDelayQueue(t, delayElem{
-				deadline: now.Add(time.Millisecond * 10),
-				val:      11,
-			}),
+			name:    "invalid context",
+			elems:   []delayElem{{delay: time.Millisecond * 10, val: 11}},
 			timeout: -time.Second,
 			wantErr: context.DeadlineExceeded,
 		},
 		{
 			name:    "empty and timeout",
-			q:       NewDelayQueue[delayElem](10),
-			timeout: time.Second,
+			timeout: time.Millisecond * 10,
 			wantErr: context.DeadlineExceeded,
 		},
 		{
-			name: "not empty but timeout",
-			q: newDelayQueue(t, delayElem{
-				deadline: now.Add(time.Second * 10),
-				val:      11,
-			}),
-			timeout: time.Second,
+			name:    "not empty but timeout",
+			elems:   []delayElem{{delay: time.Second * 10, val: 11}},
+			timeout: time.Millisecond * 10,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "not yet",
+			elems:   []delayElem{{delay: time.Millisecond * 10, val: 11}},
+			advance: time.Millisecond * 9,
+			timeout: time.Millisecond * 10,
 			wantErr: context.DeadlineExceeded,
 		},
 	}
 
-	for _, tt := range testCases {
-		tc := tt
+	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			c := clock.NewFake(time.Now())
+			q := newDelayQueue(t, c, tc.elems...)
 			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
 			defer cancel()
-			ele, err := tc.q.Dequeue(ctx)
-			assert.Equal(t, tc.wantErr, err)
-			if err != nil {
-				return
+			ch := dequeueAsync(ctx, q)
+			if tc.advance > 0 {
+				c.BlockUntil(1)
+				c.Advance(tc.advance)
 			}
-			assert.Equal(t, tc.wantVal, ele.val)
+			res := <-ch
+			assert.Equal(t, tc.wantErr, res.err)
+			assert.Equal(t, tc.wantVal, res.ele.val)
 		})
 	}
 
 	// 最开始没有元素，然后进去了一个元素
 	t.Run("dequeue while enqueue", func(t *testing.T) {
-		q := NewDelayQueue[delayElem](3)
-		go func() {
-			time.Sleep(time.Millisecond * 500)
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-			defer cancel()
-			err := q.Enqueue(ctx, delayElem{
-				val:      123,
-				deadline: time.Now().Add(time.Millisecond * 100),
-			})
-			require.NoError(t, err)
-		}()
+		t.Parallel()
+		c := clock.NewFake(time.Now())
+		q := NewDelayQueue[delayElem](3, WithClock(c))
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
-		ele, err := q.Dequeue(ctx)
+		ch := dequeueAsync(ctx, q)
+		_, err := q.Enqueue(ctx, delayElem{val: 123, delay: time.Millisecond * 100})
 		require.NoError(t, err)
-		require.Equal(t, 123, ele.val)
+		c.BlockUntil(1)
+		c.Advance(time.Millisecond * 100)
+		res := <-ch
+		require.NoError(t, res.err)
+		require.Equal(t, 123, res.ele.val)
 	})
 
 	// 进去了一个更加短超时时间的元素
 	// 于是后面两个都会拿出来，但是时间短的会先拿出来
 	t.Run("enqueue short ele", func(t *testing.T) {
-		q := NewDelayQueue[delayElem](3)
+		t.Parallel()
+		c := clock.NewFake(time.Now())
+		q := NewDelayQueue[delayElem](0, WithClock(c))
 		// 长时间过期的元素
-		err := q.Enqueue(context.Background(), delayElem{
-			val:      234,
-			deadline: time.Now().Add(time.Second),
-		})
+		_, err := q.Enqueue(context.Background(), delayElem{val: 234, delay: time.Second})
 		require.NoError(t, err)
-
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+		ch := make(chan dequeueResult, 3)
 		go func() {
-			time.Sleep(time.Millisecond * 200)
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-			defer cancel()
-			err := q.Enqueue(ctx, delayElem{
-				val:      123,
-				deadline: time.Now().Add(time.Millisecond * 300),
-			})
-			require.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				ele, err := q.Dequeue(ctx)
+				ch <- dequeueResult{ele: ele, err: err}
+			}
 		}()
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
-		defer cancel()
-		// 先拿出短时间的
-		ele, err := q.Dequeue(ctx)
+		// 等待长时间过期的元素的时候，进来了一个短时间的元素
+		c.BlockUntil(1)
+		_, err = q.Enqueue(ctx, delayElem{val: 123, delay: time.Millisecond * 300})
 		require.NoError(t, err)
-		require.Equal(t, 123, ele.val)
-		require.True(t, ele.deadline.Before(time.Now()))
+		c.Advance(time.Second)
 
+		// 先拿出短时间的
+		res := <-ch
+		require.NoError(t, res.err)
+		require.Equal(t, 123, res.ele.val)
 		// 再拿出长时间的
-		ele, err = q.Dequeue(ctx)
-		require.NoError(t, err)
-		require.Equal(t, 234, ele.val)
-		require.True(t, ele.deadline.Before(time.Now()))
-
+		res = <-ch
+		require.NoError(t, res.err)
+		require.Equal(t, 234, res.ele.val)
 		// 没有元素了，会超时
-		_, err = q.Dequeue(ctx)
-		require.Equal(t, context.DeadlineExceeded, err)
+		res = <-ch
+		require.Equal(t, context.DeadlineExceeded, res.err)
 	})
 
 	t.Run("dequeue two elements concurrently with larger delay intervals", func(t *testing.T) {
 		t.Parallel()
 
 		capacity := 2
-		q := NewDelayQueue[delayElem](capacity)
-
+		c := clock.NewFake(time.Now())
 		// 使队列处于有元素状态，元素间的截止日期有较大时间差
-		elem1 := delayElem{
-			val:      10001,
-			deadline: time.Now().Add(50 * time.Millisecond),
-		}
-		require.NoError(t, q.Enqueue(context.Background(), elem1))
-
-		elem2 := delayElem{
-			val:      10002,
-			deadline: time.Now().Add(500 * time.Millisecond),
-		}
-		require.NoError(t, q.Enqueue(context.Background(), elem2))
+		elem1 := delayElem{val: 10001, delay: 50 * time.Millisecond}
+		elem2 := delayElem{val: 10002, delay: 500 * time.Millisecond}
+		q := newDelayQueue(t, c, elem1, elem2)
 
 		// 并发出队，使调用者协程并发地按照较小截止日期的元素的延迟时间进行等待
 		elemsChan := make(chan delayElem, capacity)
@@ -184,61 +171,64 @@ func TestDelayQueue_Dequeue(t *testing.T) {
 				return err
 			})
 		}
-
-		assert.NoError(t, eg.Wait())
+		c.BlockUntil(capacity)
+		c.Advance(50 * time.Millisecond)
 
 		// 一定先拿出短时间的
 		ele := <-elemsChan
 		require.Equal(t, elem1.val, ele.val)
-		require.True(t, ele.deadline.Before(time.Now()))
 
-		// 再拿出长时间的，因为并发原因多个调用者协程可能都等待具有较小截止日期的元素
-		// 防止后者未验证元素是否过期而直接将其出队
+		// 另一个调用者也被唤醒了，但是它必须验证元素是否过期，不能直接将其出队
+		c.BlockUntil(1)
+		select {
+		case ele = <-elemsChan:
+			t.Fatalf("dequeue %d before deadline", ele.val)
+		default:
+		}
+		c.Advance(450 * time.Millisecond)
 		ele = <-elemsChan
 		require.Equal(t, elem2.val, ele.val)
-		require.True(t, ele.deadline.Before(time.Now()))
+		assert.NoError(t, eg.Wait())
 	})
 }
 
 func TestDelayQueue_Enqueue(t *testing.T) {
 	t.Parallel()
-	now := time.Now()
 	testCases := []struct {
 		name    string
-		q       *DelayQueue[delayElem]
+		elems   []delayElem
 		timeout time.Duration
 		val     delayElem
 		wantErr error
 	}{
 		{
 			name:    "enqueued",
-			q:       NewDelayQueue[delayElem](3),
 			timeout: time.Second,
-			val:     delayElem{val: 123, deadline: now.Add(time.Minute)},
+			val:     delayElem{val: 123, delay: time.Minute},
 		},
 		{
 			// context 本身已经过期了
 			name:    "invalid context",
-			q:       NewDelayQueue[delayElem](3),
 			timeout: -time.Second,
-			val:     delayElem{val: 123, deadline: now.Add(time.Minute)},
+			val:     delayElem{val: 123, delay: time.Minute},
 			wantErr: context.DeadlineExceeded,
 		},
 		{
 			// enqueue 的时候阻塞住了，直到超时
 			name:    "enqueue timeout",
-			q:       newDelayQueue(t, delayElem{val: 123, deadline: now.Add(time.Minute)}),
-			timeout: time.Millisecond * 100,
-			val:     delayElem{val: 234, deadline: now.Add(time.Minute)},
+			elems:   []delayElem{{val: 123, delay: time.Minute}},
+			timeout: time.Millisecond * 10,
+			val:     delayElem{val: 234, delay: time.Minute},
 			wantErr: context.DeadlineExceeded,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			q := newDelayQueue(t, clock.NewFake(time.Now()), tc.elems...)
 			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
 			defer cancel()
-			err := tc.q.Enqueue(ctx, tc.val)
+			_, err := q.Enqueue(ctx, tc.val)
 			assert.Equal(t, tc.wantErr, err)
 		})
 	}
@@ -247,67 +237,270 @@ func TestDelayQueue_Enqueue(t *testing.T) {
 	// 在等待一段时间之后，队列元素被取走一个
 	t.Run("enqueue while dequeue", func(t *testing.T) {
 		t.Parallel()
-		q := newDelayQueue(t, delayElem{val: 123, deadline: time.Now().Add(time.Second)})
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
-			defer cancel()
-			ele, err := q.Dequeue(ctx)
-			require.NoError(t, err)
-			require.Equal(t, 123, ele.val)
-		}()
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+		c := clock.NewFake(time.Now())
+		q := newDelayQueue(t, c, delayElem{val: 123, delay: time.Second})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
-		err := q.Enqueue(ctx, delayElem{val: 345, deadline: time.Now().Add(time.Millisecond * 1500)})
+		ch := dequeueAsync(ctx, q)
+		c.BlockUntil(1)
+		c.Advance(time.Second)
+		_, err := q.Enqueue(ctx, delayElem{val: 345, delay: time.Millisecond * 500})
 		require.NoError(t, err)
+		res := <-ch
+		require.NoError(t, res.err)
+		require.Equal(t, 123, res.ele.val)
 	})
 
-	// 入队相同过期时间的元素
-	// 但是因为我们在入队的时候是分别计算 Delay 的
-	// 那么就会导致虽然过期时间是相同的，但是因为调用 Delay 有先后之分
-	// 所以会造成 dstDelay 就是要比 srcDelay 小一点
+	// 入队相同过期时间的元素，它们之间不保证出队顺序
 	t.Run("enqueue with same deadline", func(t *testing.T) {
 		t.Parallel()
-		q := NewDelayQueue[delayElem](3)
-		deadline := time.Now().Add(time.Second)
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+		c := clock.NewFake(time.Now())
+		q := NewDelayQueue[delayElem](3, WithClock(c))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
-		err := q.Enqueue(ctx, delayElem{val: 123, deadline: deadline})
-		require.NoError(t, err)
-		err = q.Enqueue(ctx, delayElem{val: 456, deadline: deadline})
+		for _, val := range []int{123, 456, 789} {
+			_, err := q.Enqueue(ctx, delayElem{val: val, delay: time.Second})
+			require.NoError(t, err)
+		}
+		c.Advance(time.Second)
+
+		var vals []int
+		for i := 0; i < 3; i++ {
+			ele, err := q.Dequeue(ctx)
+			require.NoError(t, err)
+			vals = append(vals, ele.val)
+		}
+		assert.ElementsMatch(t, []int{123, 456, 789}, vals)
+	})
+}
+
+func TestDelayQueue_PeekLen(t *testing.T) {
+	t.Parallel()
+	q := NewDelayQueue[delayElem](0)
+	_, err := q.Peek()
+	assert.Equal(t, errEmptyQueue, err)
+	for _, val := range []int{3, 1, 2} {
+		_, err = q.Enqueue(context.Background(), delayElem{val: val, delay: time.Duration(val) * time.Minute})
 		require.NoError(t, err)
-		err = q.Enqueue(ctx, delayElem{val: 789, deadline: deadline})
+	}
+	assert.Equal(t, 3, q.Len())
+	ele, err := q.Peek()
+	require.NoError(t, err)
+	// 没有到期也可以查看，并且不会出队
+	assert.Equal(t, 1, ele.val)
+	assert.Equal(t, 3, q.Len())
+}
+
+func TestDelayHandle_Cancel(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		// cancel 要取消的元素的下标，按照入队的顺序
+		cancel []int
+
+		wantCancel []bool
+		wantVals   []int
+	}{
+		{
+			name:       "cancel head",
+			cancel:     []int{0},
+			wantCancel: []bool{true},
+			wantVals:   []int{2, 3, 4},
+		},
+		{
+			name:       "cancel middle",
+			cancel:     []int{2},
+			wantCancel: []bool{true},
+			wantVals:   []int{1, 2, 4},
+		},
+		{
+			name:       "cancel last",
+			cancel:     []int{3},
+			wantCancel: []bool{true},
+			wantVals:   []int{1, 2, 3},
+		},
+		{
+			name:       "cancel twice",
+			cancel:     []int{1, 1},
+			wantCancel: []bool{true, false},
+			wantVals:   []int{1, 3, 4},
+		},
+		{
+			name:       "cancel all",
+			cancel:     []int{3, 0, 2, 1},
+			wantCancel: []bool{true, true, true, true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := clock.NewFake(time.Now())
+			q := NewDelayQueue[delayElem](4, WithClock(c))
+			var handles []*DelayHandle[delayElem]
+			for _, val := range []int{1, 2, 3, 4} {
+				h, err := q.Enqueue(context.Background(), delayElem{
+					val:   val,
+					delay: time.Duration(val) * time.Millisecond,
+				})
+				require.NoError(t, err)
+				handles = append(handles, h)
+			}
+			for i, idx := range tc.cancel {
+				assert.Equal(t, tc.wantCancel[i], handles[idx].Cancel())
+			}
+			assert.Equal(t, len(tc.wantVals), q.Len())
+			c.Advance(time.Second)
+			var vals []int
+			for q.Len() > 0 {
+				ele, err := q.Dequeue(context.Background())
+				require.NoError(t, err)
+				vals = append(vals, ele.val)
+			}
+			assert.Equal(t, tc.wantVals, vals)
+		})
+	}
+
+	// 取消唯一的元素之后，等待中的 Dequeue 不会拿到它
+	t.Run("cancel while dequeue", func(t *testing.T) {
+		t.Parallel()
+		c := clock.NewFake(time.Now())
+		q := NewDelayQueue[delayElem](0, WithClock(c))
+		h, err := q.Enqueue(context.Background(), delayElem{val: 1, delay: 100 * time.Millisecond})
 		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		ch := dequeueAsync(ctx, q)
+		c.BlockUntil(1)
+		assert.True(t, h.Cancel())
+		c.Advance(time.Second)
+		res := <-ch
+		assert.Equal(t, context.DeadlineExceeded, res.err)
+		// 已经不在队列中了
+		assert.False(t, h.Reschedule(time.Second))
+		assert.Equal(t, 0, q.Len())
+	})
 
-		ele, err := q.Dequeue(ctx)
+	// 队列满了，取消元素之后腾出位置
+	t.Run("cancel while enqueue", func(t *testing.T) {
+		t.Parallel()
+		q := NewDelayQueue[delayElem](1)
+		h, err := q.Enqueue(context.Background(), delayElem{val: 1, delay: time.Minute})
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		var eg errgroup.Group
+		eg.Go(func() error {
+			_, err := q.Enqueue(ctx, delayElem{val: 2, delay: time.Minute})
+			return err
+		})
+		assert.True(t, h.Cancel())
+		require.NoError(t, eg.Wait())
+		ele, err := q.Peek()
 		require.NoError(t, err)
-		require.Equal(t, 123, ele.val)
+		assert.Equal(t, 2, ele.val)
+	})
+}
 
-		ele, err = q.Dequeue(ctx)
+func TestDelayHandle_Reschedule(t *testing.T) {
+	t.Parallel()
+	// 把一个很久之后才到期的元素提前，等待中的 Dequeue 会马上拿到它
+	t.Run("earlier", func(t *testing.T) {
+		t.Parallel()
+		c := clock.NewFake(time.Now())
+		q := NewDelayQueue[delayElem](0, WithClock(c))
+		_, err := q.Enqueue(context.Background(), delayElem{val: 1, delay: time.Minute})
+		require.NoError(t, err)
+		h, err := q.Enqueue(context.Background(), delayElem{val: 2, delay: time.Hour})
 		require.NoError(t, err)
-		require.Equal(t, 789, ele.val)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ch := dequeueAsync(ctx, q)
+		c.BlockUntil(1)
+		assert.True(t, h.Reschedule(0))
+		res := <-ch
+		require.NoError(t, res.err)
+		assert.Equal(t, 2, res.ele.val)
+		assert.False(t, h.Reschedule(0))
+		assert.False(t, h.Cancel())
+	})
 
-		ele, err = q.Dequeue(ctx)
+	// 把即将到期的元素推迟，另一个元素先出队
+	t.Run("later", func(t *testing.T) {
+		t.Parallel()
+		c := clock.NewFake(time.Now())
+		q := NewDelayQueue[delayElem](0, WithClock(c))
+		h, err := q.Enqueue(context.Background(), delayElem{val: 1, delay: 50 * time.Millisecond})
+		require.NoError(t, err)
+		_, err = q.Enqueue(context.Background(), delayElem{val: 2, delay: 100 * time.Millisecond})
+		require.NoError(t, err)
+		assert.True(t, h.Reschedule(time.Minute))
+		c.Advance(100 * time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ele, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, ele.val)
+		assert.Equal(t, 1, q.Len())
+		head, err := q.Peek()
 		require.NoError(t, err)
-		require.Equal(t, 456, ele.val)
+		assert.Equal(t, 1, head.val)
+		c.Advance(time.Minute - 100*time.Millisecond - 1)
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
+
+func TestDelayQueue_Close(t *testing.T) {
+	t.Parallel()
+	q := newDelayQueue(t, clock.NewFake(time.Now()), delayElem{val: 1, delay: time.Minute})
+	var eg errgroup.Group
+	eg.Go(func() error {
+		_, err := q.Dequeue(context.Background())
+		return err
+	})
+	eg.Go(func() error {
+		// 队列满了，会一直阻塞
+		_, err := q.Enqueue(context.Background(), delayElem{val: 2})
+		return err
 	})
+	require.NoError(t, q.Close())
+	assert.Equal(t, ErrQueueClosed, eg.Wait())
+	require.NoError(t, q.Close())
+	_, err := q.Enqueue(context.Background(), delayElem{val: 3})
+	assert.Equal(t, ErrQueueClosed, err)
+	_, err = q.Dequeue(context.Background())
+	assert.Equal(t, ErrQueueClosed, err)
 }
 
-func newDelayQueue(t *testing.T, eles ...delayElem) *DelayQueue[delayElem] {
-	q := NewDelayQueue[delayElem](len(eles))
+func newDelayQueue(t *testing.T, c clock.Clock, eles ...delayElem) *DelayQueue[delayElem] {
+	q := NewDelayQueue[delayElem](len(eles), WithClock(c))
 	for _, ele := range eles {
-		err := q.Enqueue(context.Background(), ele)
+		_, err := q.Enqueue(context.Background(), ele)
 		require.NoError(t, err)
 	}
 	return q
 }
 
+type dequeueResult struct {
+	ele delayElem
+	err error
+}
+
+func dequeueAsync(ctx context.Context, q *DelayQueue[delayElem]) <-chan dequeueResult {
+	ch := make(chan dequeueResult, 1)
+	go func() {
+		ele, err := q.Dequeue(ctx)
+		ch <- dequeueResult{ele: ele, err: err}
+	}()
+	return ch
+}
+
 type delayElem struct {
-	deadline time.Time
-	val      int
+	delay time.Duration
+	val   int
 }
 
 func (d delayElem) Delay() time.Duration {
-	return time.Until(d.deadline)
+	return d.delay
 }
 
 func ExampleNewDelayQueue() {
@@ -315,23 +508,12 @@ func ExampleNewDelayQueue() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 	now := time.Now()
-	_ = q.Enqueue(ctx, delayElem{
-		// 3 秒后过期
-		deadline: now.Add(time.Second * 3),
-		val:      3,
-	})
-
-	_ = q.Enqueue(ctx, delayElem{
-		// 2 秒后过期
-		deadline: now.Add(time.Second * 2),
-		val:      2,
-	})
-
-	_ = q.Enqueue(ctx, delayElem{
-		// 1 秒后过期
-		deadline: now.Add(time.Second * 1),
-		val:      1,
-	})
+	// 30 毫秒后过期
+	_, _ = q.Enqueue(ctx, delayElem{delay: time.Millisecond * 30, val: 3})
+	// 20 毫秒后过期
+	_, _ = q.Enqueue(ctx, delayElem{delay: time.Millisecond * 20, val: 2})
+	// 10 毫秒后过期
+	_, _ = q.Enqueue(ctx, delayElem{delay: time.Millisecond * 10, val: 1})
 
 	var vals []int
 	val, _ := q.Dequeue(ctx)
@@ -342,7 +524,7 @@ func ExampleNewDelayQueue() {
 	vals = append(vals, val.val)
 	fmt.Println(vals)
 	duration := time.Since(now)
-	if duration > time.Second*3 {
+	if duration >= time.Millisecond*30 {
 		fmt.Println("delay!")
 	}
 	// Output:
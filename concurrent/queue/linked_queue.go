@@ -15,10 +15,13 @@
 package queue
 
 import (
+	"context"
+	"sync"
 	"sync/atomic"
 	"unsafe"
 
 	"github.com/igevin/algokit/collection/queue"
+	"github.com/igevin/algokit/internal/syncx"
 )
 
 // ConcurrentLinkedQueue 无界并发安全队列
@@ -27,14 +30,25 @@ type ConcurrentLinkedQueue[T any] struct {
 	head unsafe.Pointer
 	// *node[T]
 	tail unsafe.Pointer
+	// size 元素数量的近似值，入队和出队完成之后才会更新
+	size atomic.Int64
+
+	// waiters 在 DequeueCtx 里面等待的 goroutine 数量
+	// 只有它大于 0 的时候 Enqueue 才会加锁广播，所以不影响无锁的快速路径
+	waiters       atomic.Int64
+	mutex         *sync.Mutex
+	enqueueSignal *syncx.Cond
 }
 
 func NewConcurrentLinkedQueue[T any]() *ConcurrentLinkedQueue[T] {
 	head := &node[T]{}
 	ptr := unsafe.Pointer(head)
+	m := &sync.Mutex{}
 	return &ConcurrentLinkedQueue[T]{
-		head: ptr,
-		tail: ptr,
+		head:          ptr,
+		tail:          ptr,
+		mutex:         m,
+		enqueueSignal: syncx.NewCond(m),
 	}
 }
 
@@ -52,6 +66,11 @@ func (c *ConcurrentLinkedQueue[T]) Enqueue(t T) error {
 		if atomic.CompareAndSwapPointer(&tail.next, tailNext, newPtr) {
 			// 如果失败也不用担心，说明有人抢先一步了
 			atomic.CompareAndSwapPointer(&c.tail, tailPtr, newPtr)
+			c.size.Add(1)
+			if c.waiters.Load() > 0 {
+				c.mutex.Lock()
+				c.enqueueSignal.Broadcast()
+			}
 			return nil
 		}
 	}
@@ -73,11 +92,83 @@ func (c *ConcurrentLinkedQueue[T]) Dequeue() (T, error) {
 		headNextPtr := atomic.LoadPointer(&head.next)
 		if atomic.CompareAndSwapPointer(&c.head, headPtr, headNextPtr) {
 			headNext := (*node[T])(headNextPtr)
+			c.size.Add(-1)
 			return headNext.val, nil
 		}
 	}
 }
 
+// DequeueCtx 取出元素，队列为空的时候阻塞，直到有元素入队或者 ctx 结束
+func (c *ConcurrentLinkedQueue[T]) DequeueCtx(ctx context.Context) (T, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			var t T
+			return t, err
+		}
+		if t, err := c.Dequeue(); err == nil {
+			return t, nil
+		}
+		// 先登记为等待者，拿到信号之后再检查一次：
+		// 如果 Enqueue 没有看到我们登记，那么它的元素一定在我们再次检查之前就已经入队了
+		c.waiters.Add(1)
+		c.mutex.Lock()
+		signal := c.enqueueSignal.SignalCh()
+		t, err := c.Dequeue()
+		if err == nil {
+			c.waiters.Add(-1)
+			return t, nil
+		}
+		select {
+		case <-ctx.Done():
+			c.waiters.Add(-1)
+			return t, ctx.Err()
+		case <-signal:
+			// 有元素入队了，但是可能被别人抢走，进入下一个循环重新检查
+			c.waiters.Add(-1)
+		}
+	}
+}
+
+// Len 返回元素数量的近似值，并发修改时只能作为参考
+func (c *ConcurrentLinkedQueue[T]) Len() int {
+	// 出队计数可能先于入队计数被观察到，短暂地出现负数
+	return max(int(c.size.Load()), 0)
+}
+
+// IsEmpty 判断在调用的这一刻队列是否为空
+func (c *ConcurrentLinkedQueue[T]) IsEmpty() bool {
+	return atomic.LoadPointer(&c.head) == atomic.LoadPointer(&c.tail)
+}
+
+// Drain 原子地取出调用这一刻队列中的所有元素，按照入队的顺序返回
+// 队列为空的时候返回 nil
+func (c *ConcurrentLinkedQueue[T]) Drain() []T {
+	for {
+		headPtr := atomic.LoadPointer(&c.head)
+		tailPtr := atomic.LoadPointer(&c.tail)
+		tail := (*node[T])(tailPtr)
+		if tailNext := atomic.LoadPointer(&tail.next); tailNext != nil {
+			// 有元素已经链接上了但是 tail 还没有调整，帮忙推进 tail，把它也取走
+			atomic.CompareAndSwapPointer(&c.tail, tailPtr, tailNext)
+			continue
+		}
+		if headPtr == tailPtr {
+			return nil
+		}
+		// 把 head 直接移动到 tail，中间的节点就都归我们了
+		if !atomic.CompareAndSwapPointer(&c.head, headPtr, tailPtr) {
+			continue
+		}
+		var res []T
+		for cur := (*node[T])(headPtr); cur != tail; {
+			cur = (*node[T])(atomic.LoadPointer(&cur.next))
+			res = append(res, cur.val)
+		}
+		c.size.Add(-int64(len(res)))
+		return res
+	}
+}
+
 type node[T any] struct {
 	val T
 	// *node[T]
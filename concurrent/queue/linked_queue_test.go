@@ -15,11 +15,13 @@
 package queue
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -173,6 +175,204 @@ func TestConcurrentLinkedQueue(t *testing.T) {
 	wg.Wait()
 }
 
+func TestConcurrentLinkedQueue_DequeueCtx(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		q       func() *ConcurrentLinkedQueue[int]
+		timeout time.Duration
+
+		wantVal int
+		wantErr error
+	}{
+		{
+			name: "not empty",
+			q: func() *ConcurrentLinkedQueue[int] {
+				q := NewConcurrentLinkedQueue[int]()
+				_ = q.Enqueue(123)
+				return q
+			},
+			timeout: time.Second,
+			wantVal: 123,
+		},
+		{
+			name: "empty timeout",
+			q: func() *ConcurrentLinkedQueue[int] {
+				return NewConcurrentLinkedQueue[int]()
+			},
+			timeout: 10 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "wake up by enqueue",
+			q: func() *ConcurrentLinkedQueue[int] {
+				q := NewConcurrentLinkedQueue[int]()
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					_ = q.Enqueue(234)
+				}()
+				return q
+			},
+			timeout: time.Second,
+			wantVal: 234,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.q()
+			val, err := q.DequeueCtx(ctx)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVal, val)
+			assert.Equal(t, int64(0), q.waiters.Load())
+		})
+	}
+}
+
+func TestConcurrentLinkedQueue_LenAndDrain(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		enqueue []int
+		dequeue int
+
+		wantLen   int
+		wantDrain []int
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:      "enqueue only",
+			enqueue:   []int{1, 2, 3},
+			wantLen:   3,
+			wantDrain: []int{1, 2, 3},
+		},
+		{
+			name:      "dequeue some",
+			enqueue:   []int{1, 2, 3},
+			dequeue:   2,
+			wantLen:   1,
+			wantDrain: []int{3},
+		},
+		{
+			name:    "dequeue all",
+			enqueue: []int{1, 2},
+			dequeue: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentLinkedQueue[int]()
+			for _, v := range tc.enqueue {
+				require.NoError(t, q.Enqueue(v))
+			}
+			for i := 0; i < tc.dequeue; i++ {
+				_, err := q.Dequeue()
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantLen, q.Len())
+			assert.Equal(t, tc.wantLen == 0, q.IsEmpty())
+			assert.Equal(t, tc.wantDrain, q.Drain())
+			assert.Equal(t, 0, q.Len())
+			assert.True(t, q.IsEmpty())
+			// 取空之后队列依旧可用
+			require.NoError(t, q.Enqueue(100))
+			val, err := q.Dequeue()
+			require.NoError(t, err)
+			assert.Equal(t, 100, val)
+		})
+	}
+}
+
+// TestConcurrentLinkedQueue_DrainConcurrent 同时使用 Dequeue、DequeueCtx 和 Drain，每个元素恰好被取出一次
+func TestConcurrentLinkedQueue_DrainConcurrent(t *testing.T) {
+	t.Parallel()
+	const producers, perProducer = 4, 2000
+	const total = producers * perProducer
+	q := NewConcurrentLinkedQueue[int]()
+	var cnt atomic.Int64
+	counts := make([]atomic.Int32, total)
+	// allTaken 在所有元素都被取出之后关闭
+	allTaken := make(chan struct{})
+	take := func(v int) {
+		counts[v].Add(1)
+		if cnt.Add(1) == total {
+			close(allTaken)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				_ = q.Enqueue(p*perProducer + i)
+			}
+		}()
+	}
+	// 非阻塞的 Drain 和 Dequeue 取不到元素的时候，退化为阻塞的 DequeueCtx，避免空转
+	// ctx 被取消之后 DequeueCtx 返回错误，消费者退出
+	var consumers sync.WaitGroup
+	consumers.Add(3)
+	go func() {
+		defer consumers.Done()
+		for {
+			vals := q.Drain()
+			if len(vals) == 0 {
+				v, err := q.DequeueCtx(ctx)
+				if err != nil {
+					return
+				}
+				vals = append(vals, v)
+			}
+			for _, v := range vals {
+				take(v)
+			}
+		}
+	}()
+	go func() {
+		defer consumers.Done()
+		for {
+			v, err := q.Dequeue()
+			if err != nil {
+				if v, err = q.DequeueCtx(ctx); err != nil {
+					return
+				}
+			}
+			take(v)
+		}
+	}()
+	go func() {
+		defer consumers.Done()
+		for {
+			v, err := q.DequeueCtx(ctx)
+			if err != nil {
+				return
+			}
+			take(v)
+		}
+	}()
+	wg.Wait()
+	select {
+	case <-allTaken:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("only %d of %d elements taken", cnt.Load(), total)
+	}
+	cancel()
+	consumers.Wait()
+	for i := range counts {
+		require.Equal(t, int32(1), counts[i].Load(), "value %d", i)
+	}
+	assert.Equal(t, 0, q.Len())
+}
+
 func (c *ConcurrentLinkedQueue[T]) asSlice() []T {
 	var res []T
 	cur := (*node[T])((*node[T])(c.head).next)
@@ -0,0 +1,208 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/igevin/algokit/collection/queue"
+)
+
+// SPSCQueue 有界的单生产者单消费者队列
+// 只允许一个 goroutine 放入元素，一个 goroutine 取出元素，在这个前提下，
+// TryEnqueue 和 TryDequeue 都是 wait-free 的：不需要 CAS，也不需要重试。
+// head 只由消费者修改，tail 只由生产者修改，两者放在不同的缓存行上；
+// 双方还各自缓存了对方的位置，只有在缓存的值显示队列满或者空的时候才重新读取
+type SPSCQueue[T any] struct {
+	_ cacheLinePad
+	// head 下一个要读取的位置，只有消费者修改
+	head atomic.Uint64
+	// cachedTail 消费者缓存的 tail
+	cachedTail uint64
+	_          cacheLinePad
+	// tail 下一个要写入的位置，只有生产者修改
+	tail atomic.Uint64
+	// cachedHead 生产者缓存的 head
+	cachedHead uint64
+	_          cacheLinePad
+
+	mask uint64
+	buf  []T
+
+	// 阻塞的一方在睡眠之前设置标记，另一方看到标记之后才通过 channel 唤醒它，
+	// 所以没有人等待的时候快速路径上不会碰 channel
+	producerWaiting atomic.Bool
+	consumerWaiting atomic.Bool
+	notFull         chan struct{}
+	notEmpty        chan struct{}
+}
+
+// NewSPSCQueue 创建单生产者单消费者队列，capacity 会向上取整到 2 的幂，最小为 2
+func NewSPSCQueue[T any](capacity int) *SPSCQueue[T] {
+	c := 2
+	for c < capacity {
+		c <<= 1
+	}
+	return &SPSCQueue[T]{
+		mask:     uint64(c - 1),
+		buf:      make([]T, c),
+		notFull:  make(chan struct{}, 1),
+		notEmpty: make(chan struct{}, 1),
+	}
+}
+
+// TryEnqueue 放入元素，队列满了的时候立刻返回 queue.ErrOutOfCapacity
+// 只能由生产者调用
+func (q *SPSCQueue[T]) TryEnqueue(t T) error {
+	if q.EnqueueN([]T{t}) == 0 {
+		return queue.ErrOutOfCapacity
+	}
+	return nil
+}
+
+// EnqueueN 尽可能多地放入 ts 中的元素，返回实际放入的个数
+// 这一批元素只需要发布一次 tail，只能由生产者调用
+func (q *SPSCQueue[T]) EnqueueN(ts []T) int {
+	tail := q.tail.Load()
+	capacity := q.mask + 1
+	free := capacity - (tail - q.cachedHead)
+	if free < uint64(len(ts)) {
+		q.cachedHead = q.head.Load()
+		free = capacity - (tail - q.cachedHead)
+	}
+	n := min(free, uint64(len(ts)))
+	if n == 0 {
+		return 0
+	}
+	start := tail & q.mask
+	copied := copy(q.buf[start:], ts[:n])
+	copy(q.buf, ts[copied:n])
+	q.tail.Store(tail + n)
+	q.wake(&q.consumerWaiting, q.notEmpty)
+	return int(n)
+}
+
+// TryDequeue 取出元素，队列为空的时候立刻返回 queue.ErrEmptyQueue
+// 只能由消费者调用
+func (q *SPSCQueue[T]) TryDequeue() (T, error) {
+	var res [1]T
+	if q.DequeueN(res[:]) == 0 {
+		return res[0], queue.ErrEmptyQueue
+	}
+	return res[0], nil
+}
+
+// DequeueN 尽可能多地取出元素放到 dst 里面，返回实际取出的个数
+// 只能由消费者调用
+func (q *SPSCQueue[T]) DequeueN(dst []T) int {
+	head := q.head.Load()
+	available := q.cachedTail - head
+	if available < uint64(len(dst)) {
+		q.cachedTail = q.tail.Load()
+		available = q.cachedTail - head
+	}
+	n := min(available, uint64(len(dst)))
+	if n == 0 {
+		return 0
+	}
+	start := head & q.mask
+	end := min(start+n, q.mask+1)
+	copied := copy(dst, q.buf[start:end])
+	copy(dst[copied:n], q.buf[:n-uint64(copied)])
+	// 清空槽位，避免持有已经取走的元素导致内存泄露
+	clear(q.buf[start:end])
+	clear(q.buf[:n-uint64(copied)])
+	q.head.Store(head + n)
+	q.wake(&q.producerWaiting, q.notFull)
+	return int(n)
+}
+
+// Enqueue 放入元素，队列满的时候阻塞，直到有空位或者 ctx 结束
+// 只能由生产者调用
+func (q *SPSCQueue[T]) Enqueue(ctx context.Context, t T) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if q.TryEnqueue(t) == nil {
+			return nil
+		}
+		// 先设置标记再检查一次，保证消费者要么看到标记，要么我们看到它腾出的空位
+		q.producerWaiting.Store(true)
+		if q.TryEnqueue(t) == nil {
+			q.producerWaiting.Store(false)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			q.producerWaiting.Store(false)
+			return ctx.Err()
+		case <-q.notFull:
+		}
+	}
+}
+
+// Dequeue 取出元素，队列空的时候阻塞，直到有元素或者 ctx 结束
+// 只能由消费者调用
+func (q *SPSCQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			var t T
+			return t, err
+		}
+		if t, err := q.TryDequeue(); err == nil {
+			return t, nil
+		}
+		q.consumerWaiting.Store(true)
+		if t, err := q.TryDequeue(); err == nil {
+			q.consumerWaiting.Store(false)
+			return t, nil
+		}
+		select {
+		case <-ctx.Done():
+			q.consumerWaiting.Store(false)
+			var t T
+			return t, ctx.Err()
+		case <-q.notEmpty:
+		}
+	}
+}
+
+// Len 返回元素数量的近似值，并发修改时只能作为参考
+func (q *SPSCQueue[T]) Len() int {
+	head := q.head.Load()
+	tail := q.tail.Load()
+	if tail <= head {
+		return 0
+	}
+	return int(tail - head)
+}
+
+// Cap 返回向上取整之后的实际容量
+func (q *SPSCQueue[T]) Cap() int {
+	return int(q.mask + 1)
+}
+
+// wake 如果对方在等待，就唤醒它
+// channel 的容量是 1，多余的信号会被丢弃；残留的信号只会造成一次多余的检查
+func (q *SPSCQueue[T]) wake(waiting *atomic.Bool, ch chan struct{}) {
+	if waiting.Load() && waiting.CompareAndSwap(true, false) {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
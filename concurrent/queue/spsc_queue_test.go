@@ -0,0 +1,342 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSPSCQueue(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		capacity int
+		wantCap  int
+	}{
+		{name: "zero", capacity: 0, wantCap: 2},
+		{name: "power of two", capacity: 4, wantCap: 4},
+		{name: "round up", capacity: 5, wantCap: 8},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewSPSCQueue[int](tc.capacity)
+			assert.Equal(t, tc.wantCap, q.Cap())
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
+
+func TestSPSCQueue_TryEnqueueDequeue(t *testing.T) {
+	t.Parallel()
+	q := NewSPSCQueue[int](4)
+	_, err := q.TryDequeue()
+	assert.Equal(t, errEmptyQueue, err)
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 4; i++ {
+			require.NoError(t, q.TryEnqueue(round*10+i))
+		}
+		assert.Equal(t, errOutOfCapacity, q.TryEnqueue(100))
+		assert.Equal(t, 4, q.Len())
+		for i := 0; i < 4; i++ {
+			v, err := q.TryDequeue()
+			require.NoError(t, err)
+			assert.Equal(t, round*10+i, v)
+		}
+		_, err = q.TryDequeue()
+		assert.Equal(t, errEmptyQueue, err)
+	}
+}
+
+func TestSPSCQueue_EnqueueNDequeueN(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		// 先放入再取出 offset 个元素，让后续的批量操作跨越数组末尾
+		offset   int
+		enqueue  []int
+		wantIn   int
+		dequeue  int
+		wantOut  []int
+		wantLeft int
+	}{
+		{
+			name:    "empty batch",
+			enqueue: []int{},
+			dequeue: 2,
+			wantOut: []int{},
+		},
+		{
+			name:     "partial dequeue",
+			enqueue:  []int{1, 2, 3},
+			wantIn:   3,
+			dequeue:  2,
+			wantOut:  []int{1, 2},
+			wantLeft: 1,
+		},
+		{
+			name:    "over capacity",
+			enqueue: []int{1, 2, 3, 4, 5, 6},
+			wantIn:  4,
+			dequeue: 8,
+			wantOut: []int{1, 2, 3, 4},
+		},
+		{
+			name:    "wrap around",
+			offset:  3,
+			enqueue: []int{1, 2, 3, 4},
+			wantIn:  4,
+			dequeue: 4,
+			wantOut: []int{1, 2, 3, 4},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewSPSCQueue[int](4)
+			for i := 0; i < tc.offset; i++ {
+				require.NoError(t, q.TryEnqueue(-1))
+				_, err := q.TryDequeue()
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantIn, q.EnqueueN(tc.enqueue))
+			dst := make([]int, tc.dequeue)
+			n := q.DequeueN(dst)
+			assert.Equal(t, tc.wantOut, dst[:n])
+			assert.Equal(t, tc.wantLeft, q.Len())
+		})
+	}
+}
+
+func TestSPSCQueue_Enqueue(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		q       func() *SPSCQueue[int]
+		timeout time.Duration
+		wantErr error
+	}{
+		{
+			name: "not full",
+			q: func() *SPSCQueue[int] {
+				return NewSPSCQueue[int](2)
+			},
+			timeout: time.Second,
+		},
+		{
+			name: "full timeout",
+			q: func() *SPSCQueue[int] {
+				q := NewSPSCQueue[int](2)
+				q.EnqueueN([]int{1, 2})
+				return q
+			},
+			timeout: 10 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "wake up by dequeue",
+			q: func() *SPSCQueue[int] {
+				q := NewSPSCQueue[int](2)
+				q.EnqueueN([]int{1, 2})
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					_, _ = q.TryDequeue()
+				}()
+				return q
+			},
+			timeout: time.Second,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			err := tc.q().Enqueue(ctx, 3)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestSPSCQueue_Dequeue(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		q       func() *SPSCQueue[int]
+		timeout time.Duration
+		wantVal int
+		wantErr error
+	}{
+		{
+			name: "not empty",
+			q: func() *SPSCQueue[int] {
+				q := NewSPSCQueue[int](2)
+				q.EnqueueN([]int{1})
+				return q
+			},
+			timeout: time.Second,
+			wantVal: 1,
+		},
+		{
+			name: "empty timeout",
+			q: func() *SPSCQueue[int] {
+				return NewSPSCQueue[int](2)
+			},
+			timeout: 10 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "wake up by enqueue",
+			q: func() *SPSCQueue[int] {
+				q := NewSPSCQueue[int](2)
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					_ = q.TryEnqueue(2)
+				}()
+				return q
+			},
+			timeout: time.Second,
+			wantVal: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			val, err := tc.q().Dequeue(ctx)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+}
+
+// TestSPSCQueue_Concurrent 一个生产者一个消费者，混合使用单个和批量接口，元素顺序不变
+func TestSPSCQueue_Concurrent(t *testing.T) {
+	t.Parallel()
+	const total = 100000
+	q := NewSPSCQueue[int](16)
+	ctx := context.Background()
+	go func() {
+		batch := make([]int, 0, 5)
+		for i := 0; i < total; {
+			if i%3 == 0 {
+				_ = q.Enqueue(ctx, i)
+				i++
+				continue
+			}
+			batch = batch[:0]
+			for j := i; j < min(i+5, total); j++ {
+				batch = append(batch, j)
+			}
+			n := q.EnqueueN(batch)
+			if n == 0 {
+				_ = q.Enqueue(ctx, i)
+				n = 1
+			}
+			i += n
+		}
+	}()
+	dst := make([]int, 7)
+	for want := 0; want < total; {
+		if want%2 == 0 {
+			v, err := q.Dequeue(ctx)
+			require.NoError(t, err)
+			require.Equal(t, want, v)
+			want++
+			continue
+		}
+		n := q.DequeueN(dst)
+		if n == 0 {
+			// 队列为空时退回到阻塞版本，避免空转
+			v, err := q.Dequeue(ctx)
+			require.NoError(t, err)
+			dst[0], n = v, 1
+		}
+		for _, v := range dst[:n] {
+			require.Equal(t, want, v)
+			want++
+		}
+	}
+	assert.Equal(t, 0, q.Len())
+}
+
+func BenchmarkSPSC(b *testing.B) {
+	const capacity = 1024
+	b.Run("SPSCQueue", func(b *testing.B) {
+		q := NewSPSCQueue[int](capacity)
+		ctx := context.Background()
+		go func() {
+			for i := 0; i < b.N; i++ {
+				_ = q.Enqueue(ctx, i)
+			}
+		}()
+		for i := 0; i < b.N; i++ {
+			_, _ = q.Dequeue(ctx)
+		}
+	})
+	b.Run("SPSCQueue batch", func(b *testing.B) {
+		q := NewSPSCQueue[int](capacity)
+		go func() {
+			batch := make([]int, 64)
+			for i := 0; i < b.N; {
+				n := q.EnqueueN(batch[:min(len(batch), b.N-i)])
+				if n == 0 {
+					runtime.Gosched()
+				}
+				i += n
+			}
+		}()
+		dst := make([]int, 64)
+		for i := 0; i < b.N; {
+			n := q.DequeueN(dst)
+			if n == 0 {
+				runtime.Gosched()
+			}
+			i += n
+		}
+	})
+	b.Run("ConcurrentArrayQueue", func(b *testing.B) {
+		q := NewConcurrentArrayQueue[int](capacity)
+		go func() {
+			for i := 0; i < b.N; i++ {
+				for q.TryEnqueue(i) != nil {
+					runtime.Gosched()
+				}
+			}
+		}()
+		for i := 0; i < b.N; i++ {
+			for {
+				if _, err := q.TryDequeue(); err == nil {
+					break
+				}
+				runtime.Gosched()
+			}
+		}
+	})
+	b.Run("channel", func(b *testing.B) {
+		ch := make(chan int, capacity)
+		go func() {
+			for i := 0; i < b.N; i++ {
+				ch <- i
+			}
+		}()
+		for i := 0; i < b.N; i++ {
+			<-ch
+		}
+	})
+}
@@ -17,5 +17,12 @@ package queue
 import "time"
 
 type Delayable interface {
+	// Delay 返回元素还需要等待多久才到期
+	// DelayQueue 只在入队的时候调用一次，到期时间由队列的时钟决定
 	Delay() time.Duration
 }
+
+// cacheLinePad 用于把频繁修改的字段隔开到不同的缓存行，避免伪共享
+type cacheLinePad struct {
+	_ [64]byte
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"sync/atomic"
+
+	"github.com/igevin/algokit/collection/queue"
+)
+
+const workStealingMinCapacity = 32
+
+// WorkStealingDeque 是 Chase-Lev 无锁工作窃取双端队列
+// 只有一个所有者协程可以调用 Push 和 Pop，在底部（bottom）操作；
+// 其余任意数量的协程可以调用 Steal，从顶部（top）窃取
+// 所有者的 Push 和 Pop 在绝大多数情况下不需要 CAS，只有在和窃取者争抢最后一个元素时才需要
+// 底层是环形数组，满了之后扩容到两倍，旧数组交给 GC 回收，所以不需要担心窃取者还在读旧数组
+type WorkStealingDeque[T any] struct {
+	top    atomic.Int64
+	bottom atomic.Int64
+	array  atomic.Pointer[circularArray[T]]
+}
+
+// circularArray 的每一个槽位都是原子指针，
+// 这样窃取者读取槽位和所有者回绕写入同一个槽位时不会出现数据竞争
+type circularArray[T any] struct {
+	mask  int64
+	slots []atomic.Pointer[T]
+}
+
+func newCircularArray[T any](capacity int64) *circularArray[T] {
+	return &circularArray[T]{
+		mask:  capacity - 1,
+		slots: make([]atomic.Pointer[T], capacity),
+	}
+}
+
+func (a *circularArray[T]) capacity() int64 {
+	return a.mask + 1
+}
+
+func (a *circularArray[T]) load(i int64) *T {
+	return a.slots[i&a.mask].Load()
+}
+
+func (a *circularArray[T]) store(i int64, t *T) {
+	a.slots[i&a.mask].Store(t)
+}
+
+// grow 返回两倍容量的新数组，并把 [top, bottom) 之间的元素复制过去
+func (a *circularArray[T]) grow(top, bottom int64) *circularArray[T] {
+	res := newCircularArray[T](a.capacity() << 1)
+	for i := top; i < bottom; i++ {
+		res.store(i, a.load(i))
+	}
+	return res
+}
+
+// NewWorkStealingDeque 创建工作窃取队列，capacity 是初始容量，会向上取整到 2 的幂
+func NewWorkStealingDeque[T any](capacity int) *WorkStealingDeque[T] {
+	c := int64(workStealingMinCapacity)
+	for c < int64(capacity) {
+		c <<= 1
+	}
+	res := &WorkStealingDeque[T]{}
+	res.array.Store(newCircularArray[T](c))
+	return res
+}
+
+// Push 在底部放入元素，只能由所有者协程调用
+func (w *WorkStealingDeque[T]) Push(t T) {
+	b := w.bottom.Load()
+	top := w.top.Load()
+	a := w.array.Load()
+	if b-top >= a.capacity() {
+		a = a.grow(top, b)
+		w.array.Store(a)
+	}
+	a.store(b, &t)
+	// 先写入元素再移动 bottom，窃取者看到新的 bottom 时一定能看到元素
+	w.bottom.Store(b + 1)
+}
+
+// Pop 从底部取出元素，只能由所有者协程调用
+// 队列为空，或者最后一个元素被窃取者抢走的时候，返回 queue.ErrEmptyQueue
+func (w *WorkStealingDeque[T]) Pop() (T, error) {
+	b := w.bottom.Load() - 1
+	a := w.array.Load()
+	// 先占住 bottom，这样窃取者就不会再越过它
+	w.bottom.Store(b)
+	t := w.top.Load()
+	var zero T
+	if t > b {
+		// 队列原本就是空的，恢复 bottom
+		w.bottom.Store(b + 1)
+		return zero, queue.ErrEmptyQueue
+	}
+	val := a.load(b)
+	if t < b {
+		// 还剩下不止一个元素，不可能和窃取者冲突
+		a.store(b, nil)
+		return *val, nil
+	}
+	// 只剩最后一个元素，和窃取者通过 CAS top 来争抢
+	won := w.top.CompareAndSwap(t, t+1)
+	w.bottom.Store(b + 1)
+	if !won {
+		return zero, queue.ErrEmptyQueue
+	}
+	return *val, nil
+}
+
+// Steal 从顶部窃取元素，可以被任意协程并发调用
+// 队列为空的时候返回 queue.ErrEmptyQueue；和其他窃取者或者所有者冲突时会重试
+func (w *WorkStealingDeque[T]) Steal() (T, error) {
+	for {
+		t := w.top.Load()
+		b := w.bottom.Load()
+		if t >= b {
+			var zero T
+			return zero, queue.ErrEmptyQueue
+		}
+		a := w.array.Load()
+		val := a.load(t)
+		// CAS 成功意味着 [t] 这个位置在读取期间没有被别人取走，读到的值就是有效的
+		// 这里不能把槽位置为 nil，因为所有者可能已经回绕写入了同一个槽位
+		if w.top.CompareAndSwap(t, t+1) {
+			return *val, nil
+		}
+	}
+}
+
+// Len 返回元素数量的近似值，并发修改时只能作为参考
+func (w *WorkStealingDeque[T]) Len() int {
+	n := w.bottom.Load() - w.top.Load()
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
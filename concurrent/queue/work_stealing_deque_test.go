@@ -0,0 +1,182 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkStealingDeque(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		push      []int
+		pop       int
+		steal     int
+		wantPop   []int
+		wantSteal []int
+		wantLen   int
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:    "owner is lifo",
+			push:    []int{1, 2, 3},
+			pop:     3,
+			wantPop: []int{3, 2, 1},
+		},
+		{
+			name:      "thief is fifo",
+			push:      []int{1, 2, 3},
+			steal:     3,
+			wantSteal: []int{1, 2, 3},
+		},
+		{
+			name:      "both ends",
+			push:      []int{1, 2, 3, 4},
+			pop:       1,
+			steal:     2,
+			wantPop:   []int{4},
+			wantSteal: []int{1, 2},
+			wantLen:   1,
+		},
+		{
+			name:      "grow",
+			push:      sequence(0, workStealingMinCapacity*3),
+			pop:       2,
+			steal:     2,
+			wantPop:   []int{workStealingMinCapacity*3 - 1, workStealingMinCapacity*3 - 2},
+			wantSteal: []int{0, 1},
+			wantLen:   workStealingMinCapacity*3 - 4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := NewWorkStealingDeque[int](0)
+			for _, v := range tc.push {
+				w.Push(v)
+			}
+			var popped, stolen []int
+			for i := 0; i < tc.pop; i++ {
+				v, err := w.Pop()
+				require.NoError(t, err)
+				popped = append(popped, v)
+			}
+			for i := 0; i < tc.steal; i++ {
+				v, err := w.Steal()
+				require.NoError(t, err)
+				stolen = append(stolen, v)
+			}
+			assert.Equal(t, tc.wantPop, popped)
+			assert.Equal(t, tc.wantSteal, stolen)
+			assert.Equal(t, tc.wantLen, w.Len())
+		})
+	}
+
+	t.Run("drained", func(t *testing.T) {
+		w := NewWorkStealingDeque[int](0)
+		_, err := w.Pop()
+		assert.Equal(t, errEmptyQueue, err)
+		_, err = w.Steal()
+		assert.Equal(t, errEmptyQueue, err)
+		w.Push(1)
+		_, err = w.Steal()
+		require.NoError(t, err)
+		_, err = w.Pop()
+		assert.Equal(t, errEmptyQueue, err)
+		assert.Equal(t, 0, w.Len())
+		// 空了之后还能继续使用
+		w.Push(2)
+		v, err := w.Pop()
+		require.NoError(t, err)
+		assert.Equal(t, 2, v)
+	})
+}
+
+// TestWorkStealingDeque_Concurrent 所有者边放边取，多个窃取者同时窃取，
+// 每一个放入的元素都必须恰好被消费一次，需要配合 -race 运行
+func TestWorkStealingDeque_Concurrent(t *testing.T) {
+	t.Parallel()
+	const n, thieves = 100000, 8
+	w := NewWorkStealingDeque[int](0)
+	consumed := make([]atomic.Int32, n)
+	var total atomic.Int64
+	consume := func(v int) {
+		consumed[v].Add(1)
+		total.Add(1)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < thieves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := w.Steal()
+				if err == nil {
+					consume(v)
+					continue
+				}
+				select {
+				case <-done:
+					return
+				default:
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		w.Push(i)
+		// 时不时自己也取一些，制造和窃取者争抢最后一个元素的场景
+		if i%3 == 0 {
+			if v, err := w.Pop(); err == nil {
+				consume(v)
+			}
+		}
+	}
+	for {
+		v, err := w.Pop()
+		if err != nil {
+			break
+		}
+		consume(v)
+	}
+	close(done)
+	wg.Wait()
+
+	// 所有者取空之后，窃取者可能还在处理最后抢到的元素，等待结束后再检查
+	assert.Equal(t, int64(n), total.Load())
+	for i := range consumed {
+		require.Equal(t, int32(1), consumed[i].Load(), "value %d", i)
+	}
+}
+
+func sequence(from, to int) []int {
+	res := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		res = append(res, i)
+	}
+	return res
+}
@@ -0,0 +1,344 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timingwheel
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/igevin/algokit/concurrent/clock"
+)
+
+var ErrTimingWheelStopped = errors.New("algokit: 时间轮已经停止")
+
+const (
+	defaultTick      = time.Millisecond
+	defaultWheelSize = 64
+)
+
+// TimingWheel 分层时间轮
+// 最底层的时间轮每一格代表一个 tick，上一层的每一格代表下一层转一圈的时间，
+// 超出底层范围的任务放到上层（溢出轮）中，时间推进到对应的格子时再逐层降级，
+// 所以添加和取消任务都是 O(1) 的，和任务的数量、延迟的长短都无关。
+// 任务最多会被推迟一个 tick 执行，但是不会提前执行。
+// 任务在时间轮自己的 goroutine 中按照到期顺序依次执行，耗时的任务应该自己另起 goroutine
+type TimingWheel struct {
+	tick      time.Duration
+	wheelSize int
+	clock     clock.Clock
+	// start 创建时间轮的时间，任务的到期时间都记录为从 start 开始的 tick 数
+	start time.Time
+
+	mutex sync.Mutex
+	// wheels 从底层到上层的时间轮，上层在需要的时候才创建
+	wheels []*wheel
+	// size 还在等待中的任务数量
+	size int
+	// timer 只在有任务的时候启动，armed 表示它已经启动或者即将被 run 重新启动，
+	// 空闲的时间轮不会每个 tick 都被唤醒
+	timer   clock.Timer
+	armed   bool
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type Option func(tw *TimingWheel)
+
+// WithTick 设置最底层时间轮每一格的时间跨度，也就是时间轮的精度，默认为 1 毫秒
+func WithTick(tick time.Duration) Option {
+	return func(tw *TimingWheel) {
+		if tick > 0 {
+			tw.tick = tick
+		}
+	}
+}
+
+// WithWheelSize 设置每一层时间轮的格数，默认为 64
+func WithWheelSize(size int) Option {
+	return func(tw *TimingWheel) {
+		if size > 1 {
+			tw.wheelSize = size
+		}
+	}
+}
+
+// WithClock 设置时钟，默认为真实时钟
+func WithClock(c clock.Clock) Option {
+	return func(tw *TimingWheel) {
+		tw.clock = c
+	}
+}
+
+// NewTimingWheel 创建并启动时间轮，不再使用的时候需要调用 Stop
+func NewTimingWheel(opts ...Option) *TimingWheel {
+	tw := &TimingWheel{
+		tick:      defaultTick,
+		wheelSize: defaultWheelSize,
+		clock:     clock.Real(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(tw)
+	}
+	tw.start = tw.clock.Now()
+	tw.wheels = []*wheel{newWheel(1, tw.wheelSize, 0)}
+	// 刚创建的时间轮没有任务，定时器等到 Schedule 的时候再启动
+	tw.timer = tw.clock.NewTimer(tw.tick)
+	tw.timer.Stop()
+	go tw.run()
+	return tw
+}
+
+// Schedule 在 delay 之后执行 fn，返回的 Task 可以用来取消
+// delay <= 0 的任务会在下一个 tick 执行
+func (tw *TimingWheel) Schedule(delay time.Duration, fn func()) (*Task, error) {
+	now := tw.clock.Now()
+	elapsed := now.Sub(tw.start) + delay
+	// 向上取整，保证不会提前执行
+	expiration := int64((elapsed + tw.tick - 1) / tw.tick)
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if tw.stopped {
+		return nil, ErrTimingWheelStopped
+	}
+	if tw.size == 0 {
+		// 空闲的时候时间轮没有推进，先追上当前时间，避免之后逐格补上空闲的这段时间
+		tw.skipTo(int64(now.Sub(tw.start) / tw.tick))
+	}
+	t := &Task{
+		tw:         tw,
+		fn:         fn,
+		expiration: max(expiration, tw.wheels[0].currentTime+1),
+	}
+	tw.add(t)
+	tw.size++
+	if !tw.armed {
+		tw.armed = true
+		tw.timer.Reset(tw.untilNextTick(now))
+	}
+	return t, nil
+}
+
+// Len 返回还在等待中的任务数量
+func (tw *TimingWheel) Len() int {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	return tw.size
+}
+
+// Stop 停止时间轮，等待中的任务都不会再执行，包括和正在执行的任务同一批到期的任务
+// Stop 不会等待正在执行的任务，所以也可以在任务中调用；重复调用没有任何效果
+// 需要确认时间轮已经退出的时候，在任务之外等待 Done
+func (tw *TimingWheel) Stop() {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if !tw.stopped {
+		tw.stopped = true
+		close(tw.stop)
+	}
+}
+
+// Done 返回的 channel 在 Stop 之后、正在执行的任务返回并且时间轮退出的时候关闭
+// 不能在任务中等待它，否则会死锁
+func (tw *TimingWheel) Done() <-chan struct{} {
+	return tw.done
+}
+
+func (tw *TimingWheel) run() {
+	defer close(tw.done)
+	defer tw.timer.Stop()
+	for {
+		select {
+		case <-tw.stop:
+			return
+		case <-tw.timer.C():
+			// 按照真实流逝的时间推进，goroutine 被耽搁的时候也能一次性追上
+			now := int64(tw.clock.Now().Sub(tw.start) / tw.tick)
+			for _, t := range tw.advance(now) {
+				select {
+				case <-tw.stop:
+					return
+				default:
+				}
+				t.fn()
+			}
+			tw.mutex.Lock()
+			// 没有任务了就不再启动定时器，等 Schedule 添加任务的时候再启动
+			tw.armed = tw.size > 0
+			if tw.armed {
+				tw.timer.Reset(tw.untilNextTick(tw.clock.Now()))
+			}
+			tw.mutex.Unlock()
+		}
+	}
+}
+
+// untilNextTick 返回从 now 到下一个 tick 边界的时间
+func (tw *TimingWheel) untilNextTick(now time.Time) time.Duration {
+	return tw.tick - now.Sub(tw.start)%tw.tick
+}
+
+// advance 把时间轮推进到 now，返回到期的任务
+func (tw *TimingWheel) advance(now int64) []*Task {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	var expired []*Task
+	for cur := tw.wheels[0].currentTime + 1; cur <= now; cur++ {
+		if tw.size == 0 {
+			// 没有任务的时候直接跳过中间的每一格
+			tw.skipTo(now)
+			break
+		}
+		// 先更新所有层的当前时间，降级的任务才能落到正确的位置
+		for _, w := range tw.wheels {
+			if cur%w.tick == 0 {
+				w.currentTime = cur
+			}
+		}
+		// 从上层开始，上层降级下来的任务如果恰好在这一格到期，还能被底层取出来
+		for i := len(tw.wheels) - 1; i >= 0; i-- {
+			w := tw.wheels[i]
+			if cur%w.tick != 0 {
+				continue
+			}
+			for _, t := range w.bucket(cur).takeAll() {
+				if !tw.add(t) {
+					tw.size--
+					expired = append(expired, t)
+				}
+			}
+		}
+	}
+	return expired
+}
+
+// skipTo 把所有层的当前时间直接设置为 now，只能在没有任务的时候调用
+func (tw *TimingWheel) skipTo(now int64) {
+	if now <= tw.wheels[0].currentTime {
+		return
+	}
+	for _, w := range tw.wheels {
+		w.currentTime = now - now%w.tick
+	}
+}
+
+// add 从底层开始找到能够容纳任务的时间轮，如果任务已经到期则返回 false
+func (tw *TimingWheel) add(t *Task) bool {
+	for i := 0; ; i++ {
+		if i == len(tw.wheels) {
+			// 溢出轮的一格就是下一层转一圈的时间
+			below := tw.wheels[i-1]
+			tick := below.tick * int64(tw.wheelSize)
+			tw.wheels = append(tw.wheels, newWheel(tick, tw.wheelSize, below.currentTime-below.currentTime%tick))
+		}
+		w := tw.wheels[i]
+		if t.expiration < w.currentTime+w.tick {
+			// 只有底层会走到这里，上层能收到的任务一定超出了下层的范围
+			return false
+		}
+		if t.expiration < w.currentTime+w.interval() {
+			w.bucket(t.expiration).pushBack(t)
+			return true
+		}
+	}
+}
+
+// wheel 一层时间轮
+type wheel struct {
+	// tick 每一格的跨度，单位是最底层的 tick
+	tick int64
+	// currentTime 当前指向的格子的起始时间，是 tick 的整数倍
+	currentTime int64
+	buckets     []bucket
+}
+
+func newWheel(tick int64, size int, currentTime int64) *wheel {
+	w := &wheel{
+		tick:        tick,
+		currentTime: currentTime,
+		buckets:     make([]bucket, size),
+	}
+	for i := range w.buckets {
+		root := &w.buckets[i].root
+		root.prev, root.next = root, root
+	}
+	return w
+}
+
+func (w *wheel) interval() int64 {
+	return w.tick * int64(len(w.buckets))
+}
+
+// bucket 返回时间 expiration 所在的格子
+func (w *wheel) bucket(expiration int64) *bucket {
+	return &w.buckets[(expiration/w.tick)%int64(len(w.buckets))]
+}
+
+// Task 时间轮中的任务
+type Task struct {
+	tw *TimingWheel
+	fn func()
+	// expiration 到期时间，单位是最底层的 tick
+	expiration int64
+	// prev 和 next 构成所在格子的双向链表，不在任何格子中的时候都是 nil
+	prev, next *Task
+}
+
+// Cancel 取消任务
+// 如果任务已经到期或者已经被取消了，返回 false
+func (t *Task) Cancel() bool {
+	tw := t.tw
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if t.next == nil {
+		return false
+	}
+	t.unlink()
+	tw.size--
+	return true
+}
+
+// bucket 时间轮的一格，是一个带哨兵的双向循环链表
+type bucket struct {
+	root Task
+}
+
+// takeAll 取出所有任务，并且清空这一格
+func (b *bucket) takeAll() []*Task {
+	var res []*Task
+	for t := b.root.next; t != &b.root; {
+		next := t.next
+		t.prev, t.next = nil, nil
+		res = append(res, t)
+		t = next
+	}
+	b.root.prev, b.root.next = &b.root, &b.root
+	return res
+}
+
+func (b *bucket) pushBack(t *Task) {
+	t.prev, t.next = b.root.prev, &b.root
+	b.root.prev.next = t
+	b.root.prev = t
+}
+
+func (t *Task) unlink() {
+	t.prev.next = t.next
+	t.next.prev = t.prev
+	t.prev, t.next = nil, nil
+}
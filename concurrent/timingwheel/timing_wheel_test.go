@@ -0,0 +1,307 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timingwheel
+
+import (
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/igevin/algokit/concurrent/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testWheel 使用手动推进的时钟，记录任务的执行顺序和执行时的时间
+type testWheel struct {
+	*TimingWheel
+	clock *clock.Fake
+	start time.Time
+
+	mutex sync.Mutex
+	fired []fired
+}
+
+type fired struct {
+	id int
+	at time.Duration
+}
+
+func newTestWheel(t *testing.T, opts ...Option) *testWheel {
+	start := time.Unix(0, 0)
+	c := clock.NewFake(start)
+	tw := &testWheel{
+		clock: c,
+		start: start,
+	}
+	opts = append([]Option{WithTick(time.Millisecond), WithWheelSize(4), WithClock(c)}, opts...)
+	tw.TimingWheel = NewTimingWheel(opts...)
+	t.Cleanup(tw.Stop)
+	return tw
+}
+
+func (tw *testWheel) schedule(t *testing.T, id int, delay time.Duration) *Task {
+	task, err := tw.Schedule(delay, func() {
+		tw.mutex.Lock()
+		defer tw.mutex.Unlock()
+		tw.fired = append(tw.fired, fired{id: id, at: tw.clock.Now().Sub(tw.start)})
+	})
+	require.NoError(t, err)
+	return task
+}
+
+// advance 推进时钟，并且等待时间轮处理完到期的任务
+func (tw *testWheel) advance(d time.Duration) {
+	tw.clock.Advance(d)
+	// 时间轮处理完之后，还有任务就会重新开始等待下一个 tick，没有任务就不再启动定时器
+	for !tw.settled() {
+		runtime.Gosched()
+	}
+}
+
+func (tw *testWheel) settled() bool {
+	tw.TimingWheel.mutex.Lock()
+	armed := tw.armed
+	tw.TimingWheel.mutex.Unlock()
+	return !armed || tw.clock.Pending() > 0
+}
+
+func (tw *testWheel) firedIDs() []int {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	res := make([]int, 0, len(tw.fired))
+	for _, f := range tw.fired {
+		res = append(res, f.id)
+	}
+	return res
+}
+
+func TestTimingWheel_Schedule(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		// delays 第 i 个任务的延迟
+		delays []time.Duration
+	}{
+		{
+			name:   "lowest wheel",
+			delays: []time.Duration{3 * time.Millisecond, time.Millisecond, 2 * time.Millisecond},
+		},
+		{
+			name:   "not positive",
+			delays: []time.Duration{0, -time.Millisecond},
+		},
+		{
+			name:   "overflow wheels",
+			delays: []time.Duration{4 * time.Millisecond, 17 * time.Millisecond, 63 * time.Millisecond, 64 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:   "same expiration",
+			delays: []time.Duration{20 * time.Millisecond, 20 * time.Millisecond, 5 * time.Millisecond},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tw := newTestWheel(t)
+			for i, d := range tc.delays {
+				tw.schedule(t, i, d)
+			}
+			assert.Equal(t, len(tc.delays), tw.Len())
+			latest := slices.Max(tc.delays)
+			// 每次只推进一个 tick，任务必须恰好在到期的那个 tick 执行
+			for now := time.Millisecond; now <= latest; now += time.Millisecond {
+				tw.advance(time.Millisecond)
+			}
+			tw.advance(time.Millisecond)
+			require.Len(t, tw.fired, len(tc.delays))
+			for _, f := range tw.fired {
+				assert.Equal(t, max(tc.delays[f.id], time.Millisecond), f.at, "task %d", f.id)
+			}
+			assert.Equal(t, 0, tw.Len())
+		})
+	}
+}
+
+func TestTimingWheel_NotEarly(t *testing.T) {
+	t.Parallel()
+	tw := newTestWheel(t)
+	// 当前时间不在 tick 的边界上，任务会推迟到下一个边界，但是不会提前
+	tw.clock.Advance(500 * time.Microsecond)
+	tw.schedule(t, 0, time.Millisecond)
+	tw.advance(500 * time.Microsecond)
+	assert.Empty(t, tw.firedIDs())
+	tw.advance(time.Millisecond)
+	assert.Equal(t, []int{0}, tw.firedIDs())
+	assert.Equal(t, 2*time.Millisecond, tw.fired[0].at)
+}
+
+func TestTimingWheel_CatchUp(t *testing.T) {
+	t.Parallel()
+	tw := newTestWheel(t, WithWheelSize(8))
+	r := rand.New(rand.NewSource(1))
+	delays := make([]time.Duration, 1000)
+	for i := range delays {
+		delays[i] = time.Duration(r.Intn(5000)) * time.Millisecond
+		tw.schedule(t, i, delays[i])
+	}
+	// 一次推进很长的时间，时间轮需要一次性追上，并且按照到期时间的顺序执行
+	tw.advance(time.Hour)
+	ids := tw.firedIDs()
+	require.Len(t, ids, len(delays))
+	assert.True(t, slices.IsSortedFunc(ids, func(a, b int) int {
+		return int(max(delays[a], time.Millisecond) - max(delays[b], time.Millisecond))
+	}))
+	// 空闲之后依旧可以正常调度
+	tw.schedule(t, -1, 3*time.Millisecond)
+	tw.advance(2 * time.Millisecond)
+	assert.Len(t, tw.firedIDs(), len(delays))
+	tw.advance(time.Millisecond)
+	assert.Len(t, tw.firedIDs(), len(delays)+1)
+}
+
+// TestTimingWheel_Idle 没有任务的时候不会启动定时器，空闲很久之后也能立刻正常调度
+func TestTimingWheel_Idle(t *testing.T) {
+	t.Parallel()
+	tw := newTestWheel(t)
+	assert.Equal(t, 0, tw.clock.Pending())
+	tw.schedule(t, 0, 2*time.Millisecond)
+	assert.Equal(t, 1, tw.clock.Pending())
+	tw.advance(time.Millisecond)
+	tw.advance(time.Millisecond)
+	assert.Equal(t, []int{0}, tw.firedIDs())
+	assert.Equal(t, 0, tw.clock.Pending())
+
+	tw.advance(time.Hour)
+	assert.Equal(t, 0, tw.clock.Pending())
+	tw.schedule(t, 1, time.Millisecond)
+	tw.advance(time.Millisecond)
+	assert.Equal(t, []int{0, 1}, tw.firedIDs())
+	assert.Equal(t, time.Hour+3*time.Millisecond, tw.fired[1].at)
+}
+
+func TestTask_Cancel(t *testing.T) {
+	t.Parallel()
+	tw := newTestWheel(t)
+	lowest := tw.schedule(t, 0, 2*time.Millisecond)
+	overflow := tw.schedule(t, 1, 50*time.Millisecond)
+	tw.schedule(t, 2, 50*time.Millisecond)
+	kept := tw.schedule(t, 3, 3*time.Millisecond)
+	assert.Equal(t, 4, tw.Len())
+
+	assert.True(t, lowest.Cancel())
+	assert.False(t, lowest.Cancel())
+	// 先让任务从溢出轮降级到底层再取消
+	tw.advance(48 * time.Millisecond)
+	assert.True(t, overflow.Cancel())
+	assert.Equal(t, []int{3}, tw.firedIDs())
+	// 已经执行过的任务不能取消
+	assert.False(t, kept.Cancel())
+	assert.Equal(t, 1, tw.Len())
+
+	tw.advance(10 * time.Millisecond)
+	assert.Equal(t, []int{3, 2}, tw.firedIDs())
+	assert.Equal(t, 0, tw.Len())
+}
+
+func TestTimingWheel_ScheduleInTask(t *testing.T) {
+	t.Parallel()
+	tw := newTestWheel(t)
+	var task *Task
+	// 任务执行的时候可以调度新的任务，也可以取消其他任务
+	_, err := tw.Schedule(time.Millisecond, func() {
+		task.Cancel()
+		_, err := tw.Schedule(time.Millisecond, func() {
+			tw.mutex.Lock()
+			defer tw.mutex.Unlock()
+			tw.fired = append(tw.fired, fired{id: 1})
+		})
+		assert.NoError(t, err)
+	})
+	require.NoError(t, err)
+	task = tw.schedule(t, 2, 2*time.Millisecond)
+	tw.advance(time.Millisecond)
+	tw.advance(time.Millisecond)
+	assert.Equal(t, []int{1}, tw.firedIDs())
+}
+
+func TestTimingWheel_Stop(t *testing.T) {
+	t.Parallel()
+	tw := newTestWheel(t)
+	tw.schedule(t, 0, time.Millisecond)
+	tw.Stop()
+	tw.Stop()
+	waitDone(t, tw.TimingWheel)
+	tw.clock.Advance(time.Second)
+	assert.Empty(t, tw.firedIDs())
+	_, err := tw.Schedule(time.Millisecond, func() {})
+	assert.Equal(t, ErrTimingWheelStopped, err)
+}
+
+// TestTimingWheel_StopInTask 在任务中调用 Stop 不会阻塞，同一批到期的其他任务不会再执行
+func TestTimingWheel_StopInTask(t *testing.T) {
+	t.Parallel()
+	tw := newTestWheel(t)
+	_, err := tw.Schedule(time.Millisecond, func() {
+		tw.Stop()
+	})
+	require.NoError(t, err)
+	tw.schedule(t, 1, time.Millisecond)
+	tw.clock.Advance(time.Millisecond)
+	// 任务返回之后时间轮退出
+	waitDone(t, tw.TimingWheel)
+	assert.Empty(t, tw.firedIDs())
+	_, err = tw.Schedule(time.Millisecond, func() {})
+	assert.Equal(t, ErrTimingWheelStopped, err)
+}
+
+func waitDone(t *testing.T, tw *TimingWheel) {
+	select {
+	case <-tw.Done():
+	case <-time.After(time.Second):
+		t.Fatal("timing wheel not stopped")
+	}
+}
+
+func TestTimingWheel_RealClock(t *testing.T) {
+	t.Parallel()
+	tw := NewTimingWheel()
+	defer tw.Stop()
+	start := time.Now()
+	ch := make(chan time.Time, 1)
+	_, err := tw.Schedule(20*time.Millisecond, func() {
+		ch <- time.Now()
+	})
+	require.NoError(t, err)
+	select {
+	case at := <-ch:
+		assert.GreaterOrEqual(t, at.Sub(start), 20*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("task not fired")
+	}
+}
+
+func BenchmarkTimingWheel_Schedule(b *testing.B) {
+	tw := NewTimingWheel()
+	defer tw.Stop()
+	fn := func() {}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		task, _ := tw.Schedule(time.Duration(i%1000+1)*time.Second, fn)
+		task.Cancel()
+	}
+}
@@ -14,10 +14,16 @@
 
 package slice
 
-func calCapacity(c, l int) (int, bool) {
+// CalCapacity 根据容量 c 和长度 l 计算缩容后的容量，第二个返回值表示是否需要缩容
+// 容量不超过 64 时不缩容；不超过 2048 时，长度不足 1/4 则缩容到一半；
+// 超过 2048 时，长度不足一半则缩容到 0.625 倍
+func CalCapacity(c, l int) (int, bool) {
 	if c <= 64 {
 		return c, false
 	}
+	if l == 0 {
+		l = 1
+	}
 	if c > 2048 && (c/l >= 2) {
 		factor := 0.625
 		return int(float32(c) * float32(factor)), true
@@ -30,7 +36,7 @@ func calCapacity(c, l int) (int, bool) {
 
 func Shrink[T any](src []T) []T {
 	c, l := cap(src), len(src)
-	n, changed := calCapacity(c, l)
+	n, changed := CalCapacity(c, l)
 	if !changed {
 		return src
 	}
@@ -45,6 +45,12 @@ func TestShrink(t *testing.T) {
 			enqueueLoop: 400,
 			expectCap:   1000,
 		},
+		{
+			name:        "空切片",
+			originCap:   1000,
+			enqueueLoop: 0,
+			expectCap:   500,
+		},
 		{
 			name:        "大于2048，不足一半",
 			originCap:   3000,
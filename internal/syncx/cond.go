@@ -0,0 +1,53 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncx
+
+import "sync"
+
+// Cond 是基于 channel 的条件变量
+// 和 sync.Cond 不同，等待者拿到的是一个 channel，可以和 context 等一起 select，
+// 从而支持超时和取消
+type Cond struct {
+	signal chan struct{}
+	l      sync.Locker
+}
+
+func NewCond(l sync.Locker) *Cond {
+	return &Cond{
+		signal: make(chan struct{}),
+		l:      l,
+	}
+}
+
+// Broadcast 唤醒等待者
+// 如果没有人等待，那么什么也不会发生
+// 必须加锁之后才能调用这个方法
+// 广播之后锁会被释放，这也是为了确保用户必然是在锁范围内调用的
+func (c *Cond) Broadcast() {
+	signal := make(chan struct{})
+	old := c.signal
+	c.signal = signal
+	c.l.Unlock()
+	close(old)
+}
+
+// SignalCh 返回一个 channel，用于监听广播信号
+// 必须在锁范围内使用
+// 调用后，锁会被释放，这也是为了确保用户必然是在锁范围内调用的
+func (c *Cond) SignalCh() <-chan struct{} {
+	res := c.signal
+	c.l.Unlock()
+	return res
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 igevin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncx
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCond(t *testing.T) {
+	m := &sync.Mutex{}
+	c := NewCond(m)
+
+	m.Lock()
+	signal := c.SignalCh()
+	// SignalCh 之后锁已经释放，可以再次加锁
+	m.Lock()
+	c.Broadcast()
+
+	select {
+	case <-signal:
+	case <-time.After(time.Second):
+		assert.Fail(t, "没有收到广播")
+	}
+
+	// 广播之后会换一个新的 channel，新的等待者不会被之前的广播唤醒
+	m.Lock()
+	signal = c.SignalCh()
+	select {
+	case <-signal:
+		assert.Fail(t, "不应该收到广播")
+	default:
+	}
+}